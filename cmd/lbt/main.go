@@ -6,12 +6,16 @@ import (
 	"github.com/lspaccatrosi16/go-cli-tools/args"
 	"github.com/lspaccatrosi16/lbt/lib/commands/build"
 	"github.com/lspaccatrosi16/lbt/lib/commands/clean"
+	"github.com/lspaccatrosi16/lbt/lib/commands/config"
 	"github.com/lspaccatrosi16/lbt/lib/commands/create"
+	"github.com/lspaccatrosi16/lbt/lib/commands/schema"
+	"github.com/lspaccatrosi16/lbt/lib/commands/targets"
+	"github.com/lspaccatrosi16/lbt/lib/commands/version"
 	"github.com/lspaccatrosi16/lbt/lib/log"
 )
 
 //go:embed version
-var version string
+var lbtVersion string
 
 func setup() error {
 	args.RegisterEntry(args.NewStringEntry("config", "c", "config file", "lbt.yaml"))
@@ -19,7 +23,11 @@ func setup() error {
 	args.RegisterEntry(args.NewStringEntry("targFilter", "t", "filter build targets", ""))
 	args.RegisterEntry(args.NewBoolEntry("nc", "nc", "skip cleaning tmp folder", false))
 	args.RegisterEntry(args.NewBoolEntry("force", "force", "force a cache refresh", false))
-	args.SetVersion(version)
+	args.RegisterEntry(args.NewStringEntry("profile", "p", "build profile to apply", ""))
+	args.RegisterEntry(args.NewStringEntry("preid", "preid", "pre-release identifier used by version bump pre", ""))
+	args.RegisterEntry(args.NewStringEntry("meta", "meta", "build metadata to attach when bumping the version", ""))
+	args.RegisterEntry(args.NewBoolEntry("tag", "tag", "create a git tag after bumping the version", false))
+	args.SetVersion(lbtVersion)
 
 	return args.ParseOpts()
 }
@@ -57,6 +65,14 @@ func main() {
 		err = create.Run()
 	case "clean":
 		err = clean.Run()
+	case "config":
+		err = config.Run(a[1:])
+	case "schema":
+		err = schema.Run()
+	case "targets":
+		err = targets.Run()
+	case "version":
+		err = version.Run(a[1:])
 	default:
 		log.Fatalf("Unknown command: %s", cmd)
 	}
@@ -6,7 +6,7 @@ require gopkg.in/yaml.v3 v3.0.1
 
 require (
 	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
-	github.com/lspaccatrosi16/go-cli-tools v0.5.5 // indirect
+	github.com/lspaccatrosi16/go-cli-tools v0.5.5
 	github.com/lspaccatrosi16/go-libs v0.2.2
 	github.com/manifoldco/promptui v0.9.0 // indirect
 	golang.org/x/sys v0.13.0 // indirect
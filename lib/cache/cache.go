@@ -7,10 +7,13 @@ import (
 )
 
 type BuildMeta struct {
-	BuildTime int64    `json:"build_time"`
-	BuildName string   `json:"build_name"`
-	Hash      string   `json:"hash"`
-	Objects   []string `json:"objects"`
+	BuildTime int64                        `json:"build_time"`
+	BuildName string                       `json:"build_name"`
+	Hash      string                       `json:"hash"`
+	Profile   string                       `json:"profile"`
+	Objects   []string                     `json:"objects"`
+	Env       map[string][]string          `json:"env,omitempty"`
+	Packages  map[string]map[string]string `json:"packages,omitempty"`
 	location  string
 }
 
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/lspaccatrosi16/go-cli-tools/args"
+	"github.com/lspaccatrosi16/lbt/lib/config"
+	"github.com/lspaccatrosi16/lbt/lib/types"
+	"github.com/lspaccatrosi16/lbt/pkg/lbt"
+)
+
+func ParseConfig() (*types.BuildConfig, error) {
+	cf, err := args.GetFlagValue[string]("config")
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := args.GetFlagValue[string]("profile")
+	if err != nil {
+		return nil, err
+	}
+
+	return config.ParseConfig(cf, profile)
+}
+
+func Options() (lbt.Options, error) {
+	opts := lbt.Options{}
+	var err error
+
+	opts.ConfigPath, err = args.GetFlagValue[string]("config")
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Profile, err = args.GetFlagValue[string]("profile")
+	if err != nil {
+		return opts, err
+	}
+
+	targFilter, err := args.GetFlagValue[string]("targFilter")
+	if err != nil {
+		return opts, err
+	}
+	for _, t := range strings.Split(targFilter, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			opts.Targets = append(opts.Targets, t)
+		}
+	}
+
+	opts.Force, err = args.GetFlagValue[bool]("force")
+	if err != nil {
+		return opts, err
+	}
+
+	opts.KeepTemp, err = args.GetFlagValue[bool]("nc")
+	if err != nil {
+		return opts, err
+	}
+
+	return opts, nil
+}
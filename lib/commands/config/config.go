@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/lspaccatrosi16/lbt/lib/commands/cli"
+	lbtconfig "github.com/lspaccatrosi16/lbt/lib/config"
+	"github.com/lspaccatrosi16/lbt/lib/modules"
+	"github.com/lspaccatrosi16/lbt/lib/runner"
+	"github.com/lspaccatrosi16/lbt/lib/types"
+	"gopkg.in/yaml.v3"
+)
+
+func Run(a []string) error {
+	if len(a) == 0 {
+		return fmt.Errorf("usage: lbt config show|validate")
+	}
+
+	switch a[0] {
+	case "show":
+		return show()
+	case "validate":
+		return validate()
+	default:
+		return fmt.Errorf("unknown config command: %s", a[0])
+	}
+}
+
+func show() error {
+	cfg, err := cli.ParseConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Context.Profile != "" {
+		fmt.Printf("# profile: %s\n", cfg.Context.Profile)
+	}
+	cfg.Profiles = nil
+
+	for i, m := range cfg.Modules {
+		resolved, err := resolveModule(cfg, m)
+		if err != nil {
+			return err
+		}
+		cfg.Modules[i].Config = resolved
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	err = enc.Encode(cfg)
+	if err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func resolveModule(cfg *types.BuildConfig, m types.ModuleConfig) (map[string]interface{}, error) {
+	proto, ok := modules.Main[m.Name]
+	if !ok {
+		return m.Config, nil
+	}
+	mod := types.NewInstance(proto)
+	cm, ok := mod.(types.ConfigurableModule)
+	if !ok {
+		return m.Config, nil
+	}
+
+	err := mod.Configure(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	by, err := yaml.Marshal(cm.ResolvedConfig())
+	if err != nil {
+		return nil, err
+	}
+	defaults := map[string]interface{}{}
+	err = yaml.Unmarshal(by, &defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := map[string]interface{}{}
+	for k, v := range defaults {
+		resolved[k] = v
+	}
+	for k, v := range m.Config {
+		resolved[k] = v
+	}
+	return resolved, nil
+}
+
+func validate() error {
+	cfg, err := cli.ParseConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Workspace) == 0 {
+		return validateConfig(cfg)
+	}
+
+	members, err := lbtconfig.ParseWorkspace(cfg)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, m := range members {
+		err = validateConfig(m)
+		if err != nil {
+			fmt.Printf("%s: %s\n", m.Name, err.Error())
+			failed = true
+		}
+	}
+	if failed {
+		return fmt.Errorf("workspace %s has invalid members", cfg.Name)
+	}
+	return nil
+}
+
+func validateConfig(cfg *types.BuildConfig) error {
+	mods, err := modules.ForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	err = runner.ValidateModules(cfg, mods, cfg.Targets)
+	if err != nil {
+		return err
+	}
+
+	order, err := runner.OrderModules(cfg, mods, cfg.Targets)
+	if err != nil {
+		return err
+	}
+
+	errs := []string{}
+	for _, t := range cfg.Targets {
+		tc := cfg.ForTarget(t)
+		for _, name := range order {
+			enabled, err := tc.ModuleEnabled(name)
+			if !enabled && err == nil {
+				continue
+			}
+			mod := types.NewInstance(mods[name])
+			if err == nil {
+				err = types.CheckTarget(mod, t)
+			}
+			if err == nil {
+				err = mod.Configure(tc)
+			}
+			if err != nil && !slices.Contains(errs, err.Error()) {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+
+	targets := []string{}
+	for _, t := range cfg.Targets {
+		targets = append(targets, t.String())
+	}
+
+	fmt.Printf("%s: config is valid\n", cfg.Name)
+	fmt.Printf("  targets: %s\n", strings.Join(targets, ", "))
+	fmt.Printf("  modules: %s\n", strings.Join(order, " -> "))
+	return nil
+}
@@ -0,0 +1,15 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/lspaccatrosi16/lbt/lib/modules"
+	"github.com/lspaccatrosi16/lbt/lib/schema"
+)
+
+func Run() error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema.Generate(modules.Main))
+}
@@ -0,0 +1,76 @@
+package targets
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lspaccatrosi16/go-cli-tools/args"
+	"github.com/lspaccatrosi16/lbt/lib/commands/cli"
+	"github.com/lspaccatrosi16/lbt/lib/modules"
+	"github.com/lspaccatrosi16/lbt/lib/types"
+)
+
+func Run() error {
+	targets, err := configTargets()
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		targets = allTargets()
+	}
+
+	names := []string{}
+	for name, mod := range modules.Main {
+		if _, ok := mod.(types.TargetedModule); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "TARGET\t%s\n", strings.ToUpper(strings.Join(names, "\t")))
+	for _, t := range targets {
+		row := []string{t.String()}
+		for _, name := range names {
+			if types.CheckTarget(modules.Main[name], t) == nil {
+				row = append(row, "yes")
+			} else {
+				row = append(row, "-")
+			}
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func configTargets() ([]types.Target, error) {
+	cf, err := args.GetFlagValue[string]("config")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(cf); os.IsNotExist(err) && cf == "lbt.yaml" {
+		return nil, nil
+	}
+
+	cfg, err := cli.ParseConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Targets, nil
+}
+
+func allTargets() []types.Target {
+	targets := []types.Target{}
+	for _, o := range types.OSList() {
+		for _, a := range types.ArchList() {
+			t := types.Target{OS: o.Name, Arch: a.Name}
+			if t.Validate() == nil {
+				targets = append(targets, t)
+			}
+		}
+	}
+	return targets
+}
@@ -0,0 +1,110 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lspaccatrosi16/go-cli-tools/args"
+	"github.com/lspaccatrosi16/lbt/lib/commands/cli"
+	"github.com/lspaccatrosi16/lbt/lib/semver"
+	"github.com/lspaccatrosi16/lbt/lib/types"
+)
+
+func Run(a []string) error {
+	cfg, err := cli.ParseConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Version.Path == "" {
+		return fmt.Errorf("config file does not specify a version path")
+	}
+
+	if len(a) == 0 {
+		cur, err := readVersion(cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Println(cur.String())
+		return nil
+	}
+
+	switch a[0] {
+	case "bump":
+		if len(a) != 2 {
+			return fmt.Errorf("usage: lbt version bump major|minor|patch|pre")
+		}
+		return bump(cfg, a[1])
+	default:
+		return fmt.Errorf("unknown version command: %s", a[0])
+	}
+}
+
+func bump(cfg *types.BuildConfig, part string) error {
+	if cfg.Version.VtS != "semver" {
+		return fmt.Errorf("version bumps require version type \"semver\", got \"%s\"", cfg.Version.VtS)
+	}
+
+	preid, err := args.GetFlagValue[string]("preid")
+	if err != nil {
+		return err
+	}
+	meta, err := args.GetFlagValue[string]("meta")
+	if err != nil {
+		return err
+	}
+	tag, err := args.GetFlagValue[bool]("tag")
+	if err != nil {
+		return err
+	}
+
+	cur, err := readVersion(cfg)
+	if err != nil {
+		return err
+	}
+	prev := cur.String()
+
+	err = cur.Bump(part, preid)
+	if err != nil {
+		return err
+	}
+
+	if meta != "" {
+		cur.Build = strings.Split(meta, ".")
+	}
+
+	err = cur.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(cfg.RelCfgPath(cfg.Version.Path), []byte(cur.String()), 0644)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s -> %s\n", prev, cur.String())
+
+	if tag {
+		tagName := "v" + cur.String()
+		cmd := exec.Command("git", "tag", "-a", tagName, "-m", fmt.Sprintf("%s %s", cfg.Name, cur.String()))
+		cmd.Dir = cfg.RelCfgPath()
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("git tag failed: %s", strings.TrimSpace(string(out)))
+		}
+		fmt.Printf("tagged %s\n", tagName)
+	}
+
+	return nil
+}
+
+func readVersion(cfg *types.BuildConfig) (*semver.Version, error) {
+	by, err := os.ReadFile(cfg.RelCfgPath(cfg.Version.Path))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return semver.Parse(string(by))
+}
@@ -4,34 +4,47 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 
-	"github.com/lspaccatrosi16/go-cli-tools/args"
 	"github.com/lspaccatrosi16/lbt/lib/types"
+	"github.com/lspaccatrosi16/lbt/lib/validate"
 	"gopkg.in/yaml.v3"
 )
 
-func ParseConfig() (*types.BuildConfig, error) {
-	cf, err := args.GetFlagValue[string]("config")
-	if err != nil {
-		return nil, err
+func ParseConfig(path string, profile string) (*types.BuildConfig, error) {
+	if path == "" || path == defaultConfig {
+		path = defaultConfig
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if _, err := os.Stat(defaultWorkspace); err == nil {
+				path = defaultWorkspace
+			}
+		}
 	}
 
-	f, err := os.Open(cf)
+	return ParseFile(path, profile)
+}
+
+const defaultConfig = "lbt.yaml"
+const defaultWorkspace = "lbt.work.yaml"
+
+func ParseFile(path string, profile string) (*types.BuildConfig, error) {
+	cfgAPath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
-	cfgAPath, err := filepath.Abs(cf)
+	config, err := loadConfig(cfgAPath, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	config := types.NewBuildConfig(filepath.Dir(cfgAPath))
-	err = yaml.NewDecoder(f).Decode(config)
-	if err != nil {
-		return nil, err
+	if profile != "" && len(config.Workspace) == 0 {
+		err = applyProfile(config, profile)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if config.Name == "" {
@@ -40,15 +53,174 @@ func ParseConfig() (*types.BuildConfig, error) {
 		return nil, fmt.Errorf("name field cannot contain '.'")
 	}
 
-	if len(config.Targets) == 0 {
+	if len(config.Workspace) > 0 {
+		if len(config.Modules) > 0 {
+			return nil, fmt.Errorf("workspace config %s cannot define modules", path)
+		}
+	} else if len(config.Targets) == 0 {
 		return nil, fmt.Errorf("no targets provided")
 	}
 
-	for _, t := range config.Targets {
-		if err := t.Validate(); err != nil {
+	for i := range config.Targets {
+		if err := config.Targets[i].Validate(); err != nil {
 			return nil, err
 		}
 	}
 
+	for p := range config.TargetEnv {
+		if err := types.ValidatePattern(p); err != nil {
+			return nil, fmt.Errorf("targetEnv: %s", err.Error())
+		}
+	}
+
+	for p := range config.Naming {
+		if err := types.ValidatePattern(p); err != nil {
+			return nil, fmt.Errorf("naming: %s", err.Error())
+		}
+	}
+	for _, t := range config.Targets {
+		names := config.NamePolicy(t)
+		if t.OS == types.Windows && names.Static == names.Import {
+			return nil, fmt.Errorf("naming: static and import libraries for %s both end in %q", t.String(), names.Static)
+		}
+	}
+
+	for _, m := range config.Modules {
+		for _, p := range m.Targets {
+			if err := types.ValidatePattern(p); err != nil {
+				return nil, fmt.Errorf("module %s: targets: %s", m.Name, err.Error())
+			}
+		}
+		for p := range m.Overrides {
+			if err := types.ValidatePattern(p); err != nil {
+				return nil, fmt.Errorf("module %s: overrides: %s", m.Name, err.Error())
+			}
+		}
+	}
+
+	config.Context = types.NewBuildContext(config)
+	config.Context.Profile = profile
 	return config, nil
 }
+
+func loadConfig(path string, seen []string) (*types.BuildConfig, error) {
+	if slices.Contains(seen, path) {
+		return nil, fmt.Errorf("config %s extends itself (%s)", path, strings.Join(append(seen, path), " -> "))
+	}
+
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	err = yaml.Unmarshal(by, &node)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	dir := filepath.Dir(path)
+	config := types.NewBuildConfig(dir)
+	if len(node.Content) == 0 {
+		return config, nil
+	}
+
+	var raw interface{}
+	err = node.Decode(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	pos := validate.Positions(&node, displayPath(path))
+	errs := validate.Validate(reflect.TypeFor[types.BuildConfig](), raw, pos)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config:\n%s", errs.Error())
+	}
+
+	err = node.Decode(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	for i := range config.Modules {
+		config.Modules[i].Positions = validate.Sub(pos, fmt.Sprintf("modules[%d]", i))
+	}
+	for name, profile := range config.Profiles {
+		for i := range profile.Modules {
+			profile.Modules[i].Positions = validate.Sub(pos, fmt.Sprintf("profiles.%s.modules[%d]", name, i))
+		}
+	}
+
+	if len(config.Extends) == 0 && len(config.Include) == 0 {
+		return config, nil
+	}
+
+	merged := types.NewBuildConfig(dir)
+	for _, b := range config.Extends {
+		if err := mergeBase(merged, dir, b, append(seen, path)); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.Targets) > 0 {
+		merged.Targets = nil
+	}
+	for _, b := range config.Include {
+		if err := mergeBase(merged, dir, b, append(seen, path)); err != nil {
+			return nil, err
+		}
+	}
+
+	mergeConfig(merged, config)
+	merged.Extends = nil
+	merged.Include = nil
+	return merged, nil
+}
+
+func mergeBase(merged *types.BuildConfig, dir, b string, seen []string) error {
+	bp := b
+	if !filepath.IsAbs(bp) {
+		bp = filepath.Join(dir, bp)
+	}
+
+	base, err := loadConfig(bp, seen)
+	if err != nil {
+		return err
+	}
+
+	rebase := func(p string) (string, error) {
+		if filepath.IsAbs(p) {
+			return p, nil
+		}
+		return filepath.Rel(dir, base.RelCfgPath(p))
+	}
+	for i, d := range base.IncludeDirs {
+		if base.IncludeDirs[i], err = rebase(d); err != nil {
+			return err
+		}
+	}
+	for i, d := range base.ModulePaths {
+		if base.ModulePaths[i], err = rebase(d); err != nil {
+			return err
+		}
+	}
+	if base.Version.Path != "" {
+		if base.Version.Path, err = rebase(base.Version.Path); err != nil {
+			return err
+		}
+	}
+
+	mergeConfig(merged, base)
+	return nil
+}
+
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lspaccatrosi16/lbt/lib/types"
+	"github.com/lspaccatrosi16/lbt/lib/validate"
+)
+
+func mergeConfig(base, overlay *types.BuildConfig) {
+	if overlay.Name != "" {
+		base.Name = overlay.Name
+	}
+
+	for _, t := range overlay.Targets {
+		if !slices.Contains(base.Targets, t) {
+			base.Targets = append(base.Targets, t)
+		}
+	}
+
+	base.Modules = mergeModules(base.Modules, overlay.Modules)
+
+	for _, d := range overlay.ModulePaths {
+		if !slices.Contains(base.ModulePaths, d) {
+			base.ModulePaths = append(base.ModulePaths, d)
+		}
+	}
+
+	for _, d := range overlay.IncludeDirs {
+		if !slices.Contains(base.IncludeDirs, d) {
+			base.IncludeDirs = append(base.IncludeDirs, d)
+		}
+	}
+
+	base.Env = mergeEnv(base.Env, overlay.Env)
+	for p, env := range overlay.TargetEnv {
+		if base.TargetEnv == nil {
+			base.TargetEnv = map[string]map[string]string{}
+		}
+		base.TargetEnv[p] = mergeEnv(base.TargetEnv[p], env)
+	}
+	for p, n := range overlay.Naming {
+		if base.Naming == nil {
+			base.Naming = map[string]types.Naming{}
+		}
+		base.Naming[p] = base.Naming[p].Merge(n)
+	}
+	if overlay.EnvPassthrough != nil {
+		if base.EnvPassthrough == nil {
+			base.EnvPassthrough = types.StringList{}
+		}
+		for _, v := range overlay.EnvPassthrough {
+			if !slices.Contains(base.EnvPassthrough, v) {
+				base.EnvPassthrough = append(base.EnvPassthrough, v)
+			}
+		}
+	}
+
+	if overlay.Version.Path != "" {
+		base.Version.Path = overlay.Version.Path
+	}
+	if overlay.Version.VtS != "" {
+		base.Version.VtS = overlay.Version.VtS
+	}
+
+	for name, op := range overlay.Profiles {
+		if base.Profiles == nil {
+			base.Profiles = map[string]types.Profile{}
+		}
+		bp := base.Profiles[name]
+		if len(op.Targets) > 0 {
+			bp.Targets = op.Targets
+		}
+		bp.Modules = mergeModules(bp.Modules, op.Modules)
+		base.Profiles[name] = bp
+	}
+}
+
+func applyProfile(config *types.BuildConfig, name string) error {
+	profile, ok := config.Profiles[name]
+	if !ok {
+		available := []string{}
+		for p := range config.Profiles {
+			available = append(available, p)
+		}
+		sort.Strings(available)
+		if len(available) == 0 {
+			return fmt.Errorf("unknown profile %s: config file does not define any profiles", name)
+		}
+		return fmt.Errorf("unknown profile %s (available: %s)", name, strings.Join(available, ", "))
+	}
+
+	if len(profile.Targets) > 0 {
+		config.Targets = slices.Clone(profile.Targets)
+	}
+
+	config.Modules = mergeModules(config.Modules, profile.Modules)
+	return nil
+}
+
+func mergeModules(base, overlay []types.ModuleConfig) []types.ModuleConfig {
+	merged := slices.Clone(base)
+
+	for _, om := range overlay {
+		idx := slices.IndexFunc(merged, func(m types.ModuleConfig) bool {
+			return m.Name == om.Name
+		})
+		if idx < 0 {
+			merged = append(merged, om)
+			continue
+		}
+		bm := merged[idx]
+		bm.Config = types.MergeMaps(bm.Config, om.Config)
+		if len(om.Targets) > 0 {
+			bm.Targets = om.Targets
+		}
+		if om.When != "" {
+			bm.When = om.When
+		}
+		bm.Env = mergeEnv(bm.Env, om.Env)
+		if len(om.Hooks.Before) > 0 {
+			bm.Hooks.Before = om.Hooks.Before
+		}
+		if len(om.Hooks.After) > 0 {
+			bm.Hooks.After = om.Hooks.After
+		}
+		if len(om.Hooks.OnFailure) > 0 {
+			bm.Hooks.OnFailure = om.Hooks.OnFailure
+		}
+		if len(om.Overrides) > 0 {
+			overrides := maps.Clone(bm.Overrides)
+			if overrides == nil {
+				overrides = map[string]map[string]interface{}{}
+			}
+			for p, o := range om.Overrides {
+				overrides[p] = types.MergeMaps(overrides[p], o)
+			}
+			bm.Overrides = overrides
+		}
+		bm.Positions = mergePositions(bm.Positions, om.Positions)
+		merged[idx] = bm
+	}
+
+	return merged
+}
+
+func mergeEnv(base, overlay map[string]string) map[string]string {
+	if len(overlay) == 0 {
+		return base
+	}
+	out := maps.Clone(base)
+	if out == nil {
+		out = map[string]string{}
+	}
+	maps.Copy(out, overlay)
+	return out
+}
+
+func mergePositions(base, overlay map[string]validate.Position) map[string]validate.Position {
+	out := make(map[string]validate.Position, len(base)+len(overlay))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range overlay {
+		out[k] = v
+	}
+	return out
+}
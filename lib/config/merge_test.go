@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func targetNames(t *testing.T, path string) string {
+	t.Helper()
+	config, err := loadConfig(path, nil)
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %s", err)
+	}
+	names := []string{}
+	for _, tg := range config.Targets {
+		names = append(names, tg.String())
+	}
+	return strings.Join(names, " ")
+}
+
+func TestExtends(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"common/common.yaml": `
+includeDirs: [cinc]
+modules:
+  - name: exec
+    config:
+      commands: []
+`,
+		"shared/base.yaml": `
+extends: ../common/common.yaml
+name: base
+targets:
+  - {os: linux, arch: amd64}
+includeDirs: [inc]
+version:
+  path: VERSION
+  type: semver
+modules:
+  - name: gobuild
+    config:
+      a: 1
+      nested: {x: 1, y: 2}
+profiles:
+  release:
+    modules:
+      - name: gobuild
+        config:
+          nested: {x: 5}
+`,
+		"lbt.yaml": `
+extends: shared/base.yaml
+name: app
+includeDirs: [inc, /abs/inc]
+modules:
+  - name: gobuild
+    config:
+      b: 2
+      nested: {y: 3}
+profiles:
+  release:
+    modules:
+      - name: gobuild
+        config:
+          b: 4
+`,
+	})
+
+	config, err := loadConfig(filepath.Join(dir, "lbt.yaml"), nil)
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %s", err)
+	}
+
+	if config.Name != "app" {
+		t.Errorf("Name = %q, want %q", config.Name, "app")
+	}
+	if len(config.Extends) != 0 || len(config.Include) != 0 {
+		t.Errorf("merged config still has extends %v and include %v", config.Extends, config.Include)
+	}
+
+	wantDirs := []string{"common/cinc", "shared/inc", "inc", "/abs/inc"}
+	if fmt.Sprint(config.IncludeDirs) != fmt.Sprint(wantDirs) {
+		t.Errorf("IncludeDirs = %v, want %v", config.IncludeDirs, wantDirs)
+	}
+
+	if config.Version.Path != filepath.Join("shared", "VERSION") || config.Version.VtS != "semver" {
+		t.Errorf("Version = %+v, want path shared/VERSION and type semver", config.Version)
+	}
+
+	if len(config.Modules) != 2 {
+		t.Fatalf("got %d modules, want 2", len(config.Modules))
+	}
+	if config.Modules[0].Name != "exec" || config.Modules[1].Name != "gobuild" {
+		t.Errorf("modules = %s, %s, want exec, gobuild", config.Modules[0].Name, config.Modules[1].Name)
+	}
+	want := "map[a:1 b:2 nested:map[x:1 y:3]]"
+	if got := fmt.Sprint(config.Modules[1].Config); got != want {
+		t.Errorf("gobuild config = %s, want %s", got, want)
+	}
+
+	release := config.Profiles["release"]
+	if len(release.Modules) != 1 {
+		t.Fatalf("release profile has %d modules, want 1", len(release.Modules))
+	}
+	want = "map[b:4 nested:map[x:5]]"
+	if got := fmt.Sprint(release.Modules[0].Config); got != want {
+		t.Errorf("release gobuild config = %s, want %s", got, want)
+	}
+}
+
+func TestExtendsTargets(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.yaml": `
+targets:
+  - {os: linux, arch: amd64}
+`,
+		"extra.yaml": `
+targets:
+  - {os: darwin, arch: arm64}
+`,
+		"inherit.yaml": `
+extends: base.yaml
+name: app
+`,
+		"replace.yaml": `
+extends: base.yaml
+name: app
+targets:
+  - {os: windows, arch: amd64}
+`,
+		"include.yaml": `
+include: [base.yaml, extra.yaml]
+name: app
+targets:
+  - {os: windows, arch: amd64}
+  - {os: linux, arch: amd64}
+`,
+		"both.yaml": `
+extends: base.yaml
+include: extra.yaml
+name: app
+targets:
+  - {os: windows, arch: amd64}
+`,
+	})
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"inherit.yaml", "linux_amd64"},
+		{"replace.yaml", "windows_amd64"},
+		{"include.yaml", "linux_amd64 darwin_arm64 windows_amd64"},
+		{"both.yaml", "darwin_arm64 windows_amd64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := targetNames(t, filepath.Join(dir, tt.file)); got != tt.want {
+				t.Errorf("targets = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendsCycle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": "extends: b.yaml\nname: a\n",
+		"b.yaml": "extends: a.yaml\n",
+	})
+
+	_, err := loadConfig(filepath.Join(dir, "a.yaml"), nil)
+	if err == nil {
+		t.Fatal("loadConfig returned no error for a cycle")
+	}
+	if !strings.Contains(err.Error(), "extends itself") {
+		t.Errorf("error = %q, want it to mention the cycle", err)
+	}
+}
+
+func TestExtendsMissing(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"lbt.yaml": "extends: nope.yaml\nname: a\n",
+	})
+
+	if _, err := loadConfig(filepath.Join(dir, "lbt.yaml"), nil); err == nil {
+		t.Fatal("loadConfig returned no error for a missing base config")
+	}
+}
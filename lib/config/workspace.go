@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lspaccatrosi16/lbt/lib/types"
+)
+
+func ParseWorkspace(ws *types.BuildConfig) ([]*types.BuildConfig, error) {
+	members := map[string]*types.BuildConfig{}
+	deps := map[string][]string{}
+	names := []string{}
+
+	for _, m := range ws.Workspace {
+		if m.Config == "" {
+			return nil, fmt.Errorf("workspace member requires config field")
+		}
+
+		cfg, err := ParseFile(ws.RelCfgPath(m.Config), ws.Context.Profile)
+		if err != nil {
+			return nil, fmt.Errorf("workspace member %s: %s", m.Config, err.Error())
+		}
+		if len(cfg.Workspace) > 0 {
+			return nil, fmt.Errorf("workspace member %s cannot itself be a workspace", m.Config)
+		}
+
+		name := m.Name
+		if name == "" {
+			name = cfg.Name
+		}
+		if _, ok := members[name]; ok {
+			return nil, fmt.Errorf("duplicate workspace member %s", name)
+		}
+
+		cfg.Context.TempDir = filepath.Join(ws.Context.TempDir, name)
+		members[name] = cfg
+		deps[name] = m.DependsOn
+		names = append(names, name)
+	}
+
+	order := []string{}
+	for _, n := range names {
+		var err error
+		order, err = orderMembers(n, deps, order, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ordered := []*types.BuildConfig{}
+	for _, n := range order {
+		cfg := members[n]
+		cfg.Context.Deps = map[string]*types.BuildContext{}
+		for _, d := range deps[n] {
+			cfg.Context.Deps[d] = members[d].Context
+		}
+		ordered = append(ordered, cfg)
+	}
+
+	return ordered, nil
+}
+
+func orderMembers(name string, deps map[string][]string, order []string, path []string) ([]string, error) {
+	if slices.Contains(path, name) {
+		return nil, fmt.Errorf("workspace dependency cycle: %s", strings.Join(append(path, name), " -> "))
+	}
+	if slices.Contains(order, name) {
+		return order, nil
+	}
+
+	reqs, ok := deps[name]
+	if !ok {
+		return nil, fmt.Errorf("workspace member %s depends on unknown member %s", path[len(path)-1], name)
+	}
+
+	for _, r := range reqs {
+		var err error
+		order, err = orderMembers(r, deps, order, append(path, name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return append(order, name), nil
+}
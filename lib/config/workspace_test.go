@@ -0,0 +1,121 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func orderAll(names []string, deps map[string][]string) ([]string, error) {
+	order := []string{}
+	for _, n := range names {
+		var err error
+		order, err = orderMembers(n, deps, order, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func TestOrderMembers(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		deps  map[string][]string
+		want  string
+	}{
+		{
+			name:  "independent",
+			names: []string{"a", "b", "c"},
+			deps:  map[string][]string{"a": nil, "b": nil, "c": nil},
+			want:  "a b c",
+		},
+		{
+			name:  "dependency listed later",
+			names: []string{"app", "lib"},
+			deps:  map[string][]string{"app": {"lib"}, "lib": nil},
+			want:  "lib app",
+		},
+		{
+			name:  "chain",
+			names: []string{"a", "b", "c"},
+			deps:  map[string][]string{"a": {"b"}, "b": {"c"}, "c": nil},
+			want:  "c b a",
+		},
+		{
+			name:  "diamond",
+			names: []string{"app", "left", "right", "core"},
+			deps: map[string][]string{
+				"app":   {"left", "right"},
+				"left":  {"core"},
+				"right": {"core"},
+				"core":  nil,
+			},
+			want: "core left right app",
+		},
+		{
+			name:  "dependency order is kept",
+			names: []string{"app", "z", "y"},
+			deps:  map[string][]string{"app": {"z", "y"}, "z": nil, "y": nil},
+			want:  "z y app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := orderAll(tt.names, tt.deps)
+			if err != nil {
+				t.Fatalf("orderMembers returned error: %s", err)
+			}
+			if got := strings.Join(order, " "); got != tt.want {
+				t.Errorf("order = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderMembersErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		deps  map[string][]string
+		want  string
+	}{
+		{
+			name:  "self",
+			names: []string{"a"},
+			deps:  map[string][]string{"a": {"a"}},
+			want:  "workspace dependency cycle: a -> a",
+		},
+		{
+			name:  "cycle",
+			names: []string{"a", "b", "c"},
+			deps:  map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}},
+			want:  "workspace dependency cycle: a -> b -> c -> a",
+		},
+		{
+			name:  "cycle below an acyclic member",
+			names: []string{"app", "a", "b"},
+			deps:  map[string][]string{"app": {"a"}, "a": {"b"}, "b": {"a"}},
+			want:  "workspace dependency cycle: app -> a -> b -> a",
+		},
+		{
+			name:  "unknown member",
+			names: []string{"app"},
+			deps:  map[string][]string{"app": {"lib"}},
+			want:  "workspace member app depends on unknown member lib",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := orderAll(tt.names, tt.deps)
+			if err == nil {
+				t.Fatalf("orderMembers = %v, want an error", order)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
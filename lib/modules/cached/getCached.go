@@ -27,7 +27,7 @@ func (g *GetCachedModule) Configure(config *types.BuildConfig) error {
 func (g *GetCachedModule) RunModule(modLogger *log.Logger, target types.Target) bool {
 	ml := modLogger.ChildLogger("getCached")
 	ml.Logln(log.Info, "Source files unchanged, using cached build artifact")
-	based := filepath.Join(target.TempDir(), "getCached")
+	based := filepath.Join(g.bc.TempDir(target), "getCached")
 	err := os.MkdirAll(based, 0755)
 	if err != nil {
 		ml.Logln(log.Error, err.Error())
@@ -56,5 +56,5 @@ func (g *GetCachedModule) TargetAgnostic() bool {
 }
 
 func (*GetCachedModule) RunOnCached() bool {
-	return true 
+	return true
 }
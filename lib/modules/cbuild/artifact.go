@@ -0,0 +1,149 @@
+package cbuild
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+type Artifact struct {
+	Name    string   `yaml:"name" validate:"required"`
+	Type    string   `yaml:"type" validate:"required,oneof=exe static shared"`
+	Sources []string `yaml:"sources"`
+	Deps    []string `yaml:"deps"`
+	Flags   []string `yaml:"flags"`
+	Libs    []string `yaml:"libs"`
+}
+
+func (a Artifact) matches(file string) bool {
+	if len(a.Sources) == 0 {
+		return true
+	}
+	for _, s := range a.Sources {
+		if matchGlob(strings.Split(s, "/"), strings.Split(file, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlob(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchGlob(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], parts[0])
+	return err == nil && ok && matchGlob(pattern[1:], parts[1:])
+}
+
+func resolveArtifacts(cfg *ModConfig) ([]Artifact, error) {
+	if len(cfg.Artifacts) == 0 {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("cbuild requires either \"name\" or \"artifacts\" to be set")
+		}
+		if cfg.Main == "" && cfg.LibraryMode == "" {
+			return nil, fmt.Errorf("cbuild requires either \"main\" or \"librarymode\" to be set")
+		} else if cfg.Main != "" && cfg.LibraryMode != "" {
+			return nil, fmt.Errorf("cbuild requires only 1 of \"main\" and \"librarymode\" to be set")
+		}
+
+		a := Artifact{Name: cfg.Name, Type: "exe"}
+		if cfg.LibraryMode != "" {
+			a.Type = cfg.LibraryMode
+		}
+		return []Artifact{a}, nil
+	}
+
+	if cfg.Main != "" || cfg.LibraryMode != "" {
+		return nil, fmt.Errorf("cbuild \"artifacts\" cannot be used with \"main\" or \"librarymode\"")
+	}
+
+	byName := map[string]Artifact{}
+	for _, a := range cfg.Artifacts {
+		if _, ok := byName[a.Name]; ok {
+			return nil, fmt.Errorf("cbuild artifact %s is defined more than once", a.Name)
+		}
+		byName[a.Name] = a
+	}
+
+	for _, a := range cfg.Artifacts {
+		for _, d := range a.Deps {
+			dep, ok := byName[d]
+			if !ok {
+				return nil, fmt.Errorf("cbuild artifact %s depends on unknown artifact %s", a.Name, d)
+			}
+			if dep.Type == "exe" {
+				return nil, fmt.Errorf("cbuild artifact %s cannot depend on executable %s", a.Name, d)
+			}
+		}
+	}
+
+	order := []Artifact{}
+	state := map[string]int{}
+	var visit func(a Artifact, chain []string) error
+	visit = func(a Artifact, chain []string) error {
+		chain = append(chain, a.Name)
+		switch state[a.Name] {
+		case 1:
+			return fmt.Errorf("cbuild artifacts have a dependency cycle: %s", strings.Join(chain, " -> "))
+		case 2:
+			return nil
+		}
+		state[a.Name] = 1
+		for _, d := range a.Deps {
+			if err := visit(byName[d], chain); err != nil {
+				return err
+			}
+		}
+		state[a.Name] = 2
+		order = append(order, a)
+		return nil
+	}
+
+	for _, a := range cfg.Artifacts {
+		if err := visit(a, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func (b *CbuildModule) linkDeps(a Artifact) []Artifact {
+	byName := map[string]Artifact{}
+	for _, o := range b.artifacts {
+		byName[o.Name] = o
+	}
+
+	deps := []Artifact{}
+	var walk func(a Artifact)
+	walk = func(a Artifact) {
+		for _, d := range a.Deps {
+			dep := byName[d]
+			deps = append(deps, dep)
+			if dep.Type == "static" {
+				walk(dep)
+			}
+		}
+	}
+	walk(a)
+
+	res := []Artifact{}
+	for i := len(deps) - 1; i >= 0; i-- {
+		if !slices.ContainsFunc(res, func(o Artifact) bool { return o.Name == deps[i].Name }) {
+			res = append(res, deps[i])
+		}
+	}
+	slices.Reverse(res)
+	return res
+}
@@ -0,0 +1,176 @@
+package cbuild
+
+import (
+	"strings"
+	"testing"
+)
+
+func artifactNames(l []Artifact) string {
+	names := []string{}
+	for _, a := range l {
+		names = append(names, a.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestArtifactMatches(t *testing.T) {
+	tests := []struct {
+		sources []string
+		file    string
+		want    bool
+	}{
+		{nil, "src/main.c", true},
+		{[]string{"main.c"}, "main.c", true},
+		{[]string{"main.c"}, "src/main.c", false},
+		{[]string{"*.c"}, "main.c", true},
+		{[]string{"*.c"}, "src/main.c", false},
+		{[]string{"src/*.c"}, "src/main.c", true},
+		{[]string{"src/*.c"}, "src/net/http.c", false},
+		{[]string{"src/**"}, "src/net/http.c", true},
+		{[]string{"src/**"}, "src", true},
+		{[]string{"src/**/*.c"}, "src/main.c", true},
+		{[]string{"src/**/*.c"}, "src/net/http/client.c", true},
+		{[]string{"src/**/*.c"}, "lib/main.c", false},
+		{[]string{"**/test_*.c"}, "test_a.c", true},
+		{[]string{"**/test_*.c"}, "a/b/test_a.c", true},
+		{[]string{"**/test_*.c"}, "a/b/main.c", false},
+		{[]string{"lib/*.c", "app/*.c"}, "app/main.c", true},
+		{[]string{"[.c"}, "[.c", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.sources, ",")+" "+tt.file, func(t *testing.T) {
+			a := Artifact{Name: "a", Sources: tt.sources}
+			if got := a.matches(tt.file); got != tt.want {
+				t.Errorf("matches(%q) with sources %q = %v, want %v", tt.file, tt.sources, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveArtifacts(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ModConfig
+		want    string
+		wantErr string
+	}{
+		{
+			name: "single executable",
+			cfg:  ModConfig{Name: "app", Main: "main.c"},
+			want: "app",
+		},
+		{
+			name: "single library",
+			cfg:  ModConfig{Name: "core", LibraryMode: "static"},
+			want: "core",
+		},
+		{
+			name:    "no name",
+			cfg:     ModConfig{Main: "main.c"},
+			wantErr: `cbuild requires either "name" or "artifacts" to be set`,
+		},
+		{
+			name:    "main and artifacts",
+			cfg:     ModConfig{Main: "main.c", Artifacts: []Artifact{{Name: "app", Type: "exe"}}},
+			wantErr: `cbuild "artifacts" cannot be used with "main" or "librarymode"`,
+		},
+		{
+			name: "dependencies first",
+			cfg: ModConfig{Artifacts: []Artifact{
+				{Name: "app", Type: "exe", Deps: []string{"net", "core"}},
+				{Name: "net", Type: "shared", Deps: []string{"core"}},
+				{Name: "core", Type: "static"},
+			}},
+			want: "core net app",
+		},
+		{
+			name: "duplicate",
+			cfg: ModConfig{Artifacts: []Artifact{
+				{Name: "core", Type: "static"},
+				{Name: "core", Type: "shared"},
+			}},
+			wantErr: "cbuild artifact core is defined more than once",
+		},
+		{
+			name: "unknown dependency",
+			cfg: ModConfig{Artifacts: []Artifact{
+				{Name: "app", Type: "exe", Deps: []string{"core"}},
+			}},
+			wantErr: "cbuild artifact app depends on unknown artifact core",
+		},
+		{
+			name: "executable dependency",
+			cfg: ModConfig{Artifacts: []Artifact{
+				{Name: "tool", Type: "exe"},
+				{Name: "app", Type: "exe", Deps: []string{"tool"}},
+			}},
+			wantErr: "cbuild artifact app cannot depend on executable tool",
+		},
+		{
+			name: "cycle",
+			cfg: ModConfig{Artifacts: []Artifact{
+				{Name: "a", Type: "static", Deps: []string{"b"}},
+				{Name: "b", Type: "static", Deps: []string{"a"}},
+			}},
+			wantErr: "cbuild artifacts have a dependency cycle: a -> b -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveArtifacts(&tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveArtifacts returned error: %s", err)
+			}
+			if names := artifactNames(got); names != tt.want {
+				t.Errorf("artifacts = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkDeps(t *testing.T) {
+	b := &CbuildModule{artifacts: []Artifact{
+		{Name: "util", Type: "static"},
+		{Name: "core", Type: "static", Deps: []string{"util"}},
+		{Name: "net", Type: "static", Deps: []string{"core", "util"}},
+		{Name: "gfx", Type: "shared", Deps: []string{"core"}},
+		{Name: "plug", Type: "shared", Deps: []string{"gfx"}},
+		{Name: "app", Type: "exe", Deps: []string{"net", "gfx", "core"}},
+		{Name: "tool", Type: "exe", Deps: []string{"plug"}},
+	}}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"util", ""},
+		{"core", "util"},
+		{"net", "core util"},
+		{"gfx", "core util"},
+		{"plug", "gfx"},
+		{"app", "net gfx core util"},
+		{"tool", "plug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a Artifact
+			for _, o := range b.artifacts {
+				if o.Name == tt.name {
+					a = o
+				}
+			}
+			if got := artifactNames(b.linkDeps(a)); got != tt.want {
+				t.Errorf("linkDeps(%s) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
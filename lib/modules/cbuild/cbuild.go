@@ -7,7 +7,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
+	"reflect"
+	"slices"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
 	"github.com/lspaccatrosi16/lbt/lib/types"
@@ -17,19 +18,40 @@ import (
 type CbuildModule struct {
 	bc     *types.BuildConfig
 	config *ModConfig
+	env    []string
+	tc     toolchain
+
+	artifacts []Artifact
 }
 
 type ModConfig struct {
-	Name        string   `yaml:"name"`
-	SrcDir      string   `yaml:"source"`
-	IncDir      []string `yaml:"include"`
-	Compiler    string   `yaml:"compiler"`
-	Flags       []string `yaml:"flags"`
-	Main        string   `yaml:"main"`
-	GenCC       bool     `yaml:"cc"`
-	LibraryMode string   `yaml:"librarymode"`
-	Libs        []string `yaml:"libs"`
-	LibDirs     []string `yaml:"libdirs"`
+	Name          string               `yaml:"name"`
+	SrcDir        string               `yaml:"source" validate:"required"`
+	IncDir        []string             `yaml:"include"`
+	Compiler      string               `yaml:"compiler"`
+	Cxx           string               `yaml:"cxx"`
+	Flags         []string             `yaml:"flags"`
+	CFlags        []string             `yaml:"cflags"`
+	CxxFlags      []string             `yaml:"cxxflags"`
+	CStd          string               `yaml:"cstd"`
+	CxxStd        string               `yaml:"cxxstd"`
+	Main          string               `yaml:"main"`
+	GenCC         bool                 `yaml:"cc"`
+	LibraryMode   string               `yaml:"librarymode" validate:"oneof=shared static"`
+	Libs          []string             `yaml:"libs"`
+	LibDirs       []string             `yaml:"libdirs"`
+	PkgConfig     []string             `yaml:"pkgconfig"`
+	PkgConfigPath []string             `yaml:"pkgconfigPath"`
+	ObjDir        string               `yaml:"objDir"`
+	Jobs          int                  `yaml:"jobs"`
+	Toolchains    map[string]Toolchain `yaml:"toolchains"`
+	Zig           bool                 `yaml:"zig"`
+	Artifacts     []Artifact           `yaml:"artifacts"`
+	PkgConfigFile bool                 `yaml:"pkgconfigFile"`
+	CMakeFile     bool                 `yaml:"cmakeFile"`
+	Description   string               `yaml:"description"`
+	PublicHeaders []string             `yaml:"publicHeaders"`
+	Install       bool                 `yaml:"install"`
 }
 
 type Command struct {
@@ -52,55 +74,65 @@ func (b *CbuildModule) Configure(config *types.BuildConfig) error {
 		return err
 	}
 
-	if cfg.Name == "" {
-		return fmt.Errorf("cbuild requires \"name\" to be set")
-	} else if cfg.SrcDir == "" {
-		return fmt.Errorf("cbuild requires \"source\" to be set")
-	} else if cfg.Compiler == "" {
-		return fmt.Errorf("cbuild requires \"compiler\" to be set")
-	} else if cfg.Main == "" && cfg.LibraryMode == "" {
-		return fmt.Errorf("cbuild requires either \"main\" or \"librarymode\" to be set")
-	} else if cfg.Main != "" && cfg.LibraryMode != "" {
-		return fmt.Errorf("cbuild requires only 1 of \"main\" and \"librarymode\" to be set")
+	b.env, err = config.Environ("cbuild")
+	if err != nil {
+		return fmt.Errorf("module cbuild: %s", err.Error())
 	}
 
-	if cfg.LibraryMode != "" {
-		switch cfg.LibraryMode {
-		case "shared", "static":
-			break
-		default:
-			return fmt.Errorf("value of \"%s\" is not valid for field \"library\"", cfg.LibraryMode)
+	if cfg.ObjDir == "" {
+		cfg.ObjDir = ".lbt/cbuild"
+	}
+
+	if cfg.Compiler == "" && !cfg.Zig {
+		return fmt.Errorf("cbuild requires either \"compiler\" or \"zig\" to be set")
+	}
+
+	for p := range cfg.Toolchains {
+		if err := types.ValidatePattern(p); err != nil {
+			return fmt.Errorf("cbuild toolchains: %s", err.Error())
 		}
 	}
 
+	b.artifacts, err = resolveArtifacts(cfg)
+	if err != nil {
+		return err
+	}
+
 	b.config = cfg
+	if config.Context != nil {
+		b.tc, err = b.resolveToolchain(config.Context.Target)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (b *CbuildModule) RunModule(modLogger *log.Logger, target types.Target) bool {
 	ml := modLogger.ChildLogger("cbuild")
-	if !target.CmpRuntimeOS() {
-		ml.Logln(log.Error, "cbuild does not support building for alternate OS")
-		return false
-	} else if !target.CmpRuntimeArch() {
-		ml.Logln(log.Error, "cbuild does not support building for alternate arch")
-		return false
-	}
 
 	var stdout, stderr bytes.Buffer
 	var cmds = Commands{}
 
-	buildDir := filepath.Join(target.TempDir(), "cbuild")
-	if ok := util.RunCmd(exec.Command("mkdir", "-p", buildDir), stdout, stderr, ml, ""); !ok {
+	buildDir := filepath.Join(b.bc.TempDir(target), "cbuild")
+	if ok := util.RunCmd(b.bc.Command("mkdir", "-p", buildDir), stdout, stderr, ml, ""); !ok {
 		return false
 	}
 
-	srcFiles, err := util.ScanDir(b.bc.RelCfgPath(b.config.SrcDir), ".c")
+	srcDir := b.bc.RelCfgPath(b.config.SrcDir)
+	allFiles, err := util.ScanDir(srcDir, "")
 	if err != nil {
 		ml.Logln(log.Error, err.Error())
 		return false
 	}
 
+	srcFiles := []string{}
+	for _, f := range allFiles {
+		if langOf(f) != langNone {
+			srcFiles = append(srcFiles, f)
+		}
+	}
+
 	ml.Logln(log.Info, "files", srcFiles)
 
 	var incStrs []string
@@ -118,69 +150,115 @@ func (b *CbuildModule) RunModule(modLogger *log.Logger, target types.Target) boo
 		libDirs = append(libDirs, "-L", l)
 	}
 
-	for _, f := range srcFiles {
-		args := []string{"-o", filepath.Join(buildDir, repCO(f))}
-		args = append(args, incStrs...)
-		args = append(args, libStrs...)
-		args = append(args, libDirs...)
-		args = append(args, b.config.Flags...)
-		args = append(args, "-c", filepath.Join(b.bc.RelCfgPath(b.config.SrcDir), f))
+	pkgs, err := b.resolvePackages()
+	if err != nil {
+		ml.Logln(log.Error, err.Error())
+		return false
+	}
 
-		if ok := util.RunCmd(exec.Command(b.config.Compiler, args...), stdout, stderr, ml, b.bc.RelCfgPath()); !ok {
-			return false
+	var pkgCflags, pkgLibs []string
+	versions := map[string]string{}
+	for _, p := range pkgs {
+		ml.Logf(log.Info, "using %s %s", p.name, p.version)
+		pkgCflags = append(pkgCflags, p.cflags...)
+		pkgLibs = append(pkgLibs, p.libs...)
+		versions[p.name] = p.version
+	}
+	if len(versions) > 0 {
+		b.bc.AddPackages("cbuild", versions)
+	}
+	pkgCflags = dedupe(pkgCflags, false)
+	linkLibs := slices.Concat(libDirs, libStrs, dedupe(pkgLibs, true))
+
+	objDir := filepath.Join(b.bc.RelCfgPath(b.config.ObjDir), target.String())
+	objFiles := map[string][]string{}
+	linkCxx := map[string]bool{}
+	stale := []unit{}
+	total := 0
+
+	for _, a := range b.artifacts {
+		for _, f := range srcFiles {
+			if !a.matches(f) {
+				continue
+			}
+			u := newUnit(filepath.Join(objDir, a.Name), srcDir, f)
+			if u.lang == langCxx {
+				if b.tc.cxx == nil {
+					ml.Logf(log.Error, "%s: no C++ compiler for %s, set \"cxx\"", f, target.String())
+					return false
+				}
+				linkCxx[a.Name] = true
+			}
+
+			u.args = b.compileArgs(u, a, slices.Concat(incStrs, pkgCflags))
+			objFiles[a.Name] = append(objFiles[a.Name], u.obj)
+			total++
+
+			cmds = append(cmds, Command{
+				Arguments: u.args,
+				Directory: b.bc.RelCfgPath(),
+				File:      u.src,
+				Output:    u.obj,
+			})
+
+			if u.stale() {
+				stale = append(stale, u)
+			}
 		}
 
-		absF, _ := filepath.Abs(f)
-		absO, _ := filepath.Abs(filepath.Join(buildDir, repCO(f)))
+		if len(objFiles[a.Name]) == 0 {
+			ml.Logf(log.Error, "artifact %s has no source files", a.Name)
+			return false
+		}
+	}
 
-		exe, _ := exec.LookPath(b.config.Compiler)
-		cmds = append(cmds, Command{
-			Arguments: append([]string{exe}, args...),
-			Directory: b.bc.RelCfgPath(),
-			File:      absF,
-			Output:    absO,
-		})
+	results := b.compileAll(stale)
+	failed := 0
+	for i, r := range results {
+		rel, _ := filepath.Rel(srcDir, stale[i].src)
+		if r.err != nil {
+			failed++
+			ml.Logf(log.Error, "%s: %s\n%s", rel, r.err.Error(), r.output)
+		} else if r.output != "" {
+			ml.Logf(log.Warning, "%s:\n%s", rel, r.output)
+		}
+	}
+	if failed > 0 {
+		ml.Logf(log.Error, "%d of %d files failed to compile", failed, len(stale))
+		return false
 	}
 
-	objFiles, err := util.ScanDir(buildDir, ".o")
+	ml.Logf(log.Info, "compiled %d of %d files", len(stale), total)
 
-	if err != nil {
+	l := b.layout(buildDir, target)
+	if err := l.mkdirs(); err != nil {
+		ml.Logln(log.Error, err.Error())
+		return false
+	}
+	if err := b.installHeaders(l); err != nil {
 		ml.Logln(log.Error, err.Error())
 		return false
 	}
 
-	if b.config.Main != "" {
-		args := []string{"-o", filepath.Join(buildDir, b.config.Name)}
-		args = append(args, incStrs...)
-		args = append(args, libStrs...)
-		args = append(args, libDirs...)
-		args = append(args, b.config.Flags...)
-		args = append(args, objFiles...)
-
-		if ok := util.RunCmd(exec.Command(b.config.Compiler, args...), stdout, stderr, ml, buildDir); !ok {
-			return false
+	for _, a := range b.artifacts {
+		deps := b.linkDeps(a)
+		cxx := linkCxx[a.Name]
+		for _, d := range deps {
+			cxx = cxx || linkCxx[d.Name]
 		}
-	} else if b.config.LibraryMode == "static" {
-		args := []string{"rcs", filepath.Join(buildDir, b.config.Name) + ".a"}
-		args = append(args, objFiles...)
+		linkCxx[a.Name] = cxx
 
-		if ok := util.RunCmd(exec.Command("ar", args...), stdout, stderr, ml, buildDir); !ok {
+		ml.Logf(log.Info, "linking %s", l.file(a))
+		if ok := b.linkArtifact(ml, l, a, deps, objFiles[a.Name], cxx, linkLibs, target); !ok {
 			return false
 		}
-	} else if b.config.LibraryMode == "shared" {
-		args := []string{"-o", filepath.Join(buildDir, b.config.Name) + ".so", "-shared"}
-		args = append(args, incStrs...)
-		args = append(args, libStrs...)
-		args = append(args, libDirs...)
-		args = append(args, b.config.Flags...)
-		args = append(args, objFiles...)
-		if ok := util.RunCmd(exec.Command(b.config.Compiler, args...), stdout, stderr, ml, buildDir); !ok {
-			return false
-		}
-	}
 
-	if ok := util.RunCmd(exec.Command("rm", objFiles...), stdout, stderr, ml, buildDir); !ok {
-		return false
+		if a.Type != "exe" {
+			if err := b.writePackageFiles(l, a); err != nil {
+				ml.Logln(log.Error, err.Error())
+				return false
+			}
+		}
 	}
 
 	if b.config.GenCC {
@@ -201,10 +279,6 @@ func (b *CbuildModule) RunModule(modLogger *log.Logger, target types.Target) boo
 	return true
 }
 
-func repCO(s string) string {
-	return regexp.MustCompilePOSIX(`\.c$`).ReplaceAllString(s, ".o")
-}
-
 func (b *CbuildModule) Requires() []string {
 	return nil
 }
@@ -224,3 +298,130 @@ func (b *CbuildModule) TargetAgnostic() bool {
 func (*CbuildModule) RunOnCached() bool {
 	return false
 }
+
+func (*CbuildModule) ConfigType() reflect.Type {
+	return reflect.TypeFor[ModConfig]()
+}
+
+func (b *CbuildModule) ResolvedConfig() any {
+	return b.config
+}
+
+func (*CbuildModule) SupportsTarget(t types.Target) bool {
+	return t.Supports(types.ToolchainC)
+}
+
+func (b *CbuildModule) command(argv ...string) *exec.Cmd {
+	cmd := b.bc.Command(argv[0], argv[1:]...)
+	cmd.Env = b.env
+	return cmd
+}
+
+func (b *CbuildModule) linkArtifact(ml *log.Logger, l layout, a Artifact, deps []Artifact, objFiles []string, cxx bool, libs []string, target types.Target) bool {
+	var stdout, stderr bytes.Buffer
+	dir := l.dir(a)
+	out := l.path(a)
+
+	if a.Type == "static" {
+		os.Remove(out)
+		args := append([]string{"rcs", out}, objFiles...)
+		return util.RunCmd(b.arCmd(args...), stdout, stderr, ml, dir)
+	}
+
+	full, major := "", ""
+	args := []string{"-o", out}
+	if a.Type == "shared" {
+		args = append(args, "-shared")
+		switch target.OS {
+		case types.Windows:
+			args = append(args, "-Wl,--out-implib,"+l.importLib(a))
+		case types.MacOS:
+			args = append(args, "-Wl,-install_name,@rpath/"+l.file(a))
+		default:
+			full, major = b.soVersion(target)
+			soname := l.file(a)
+			if full != "" {
+				soname += "." + major
+				args[1] += "." + full
+			}
+			args = append(args, "-Wl,-soname,"+soname)
+		}
+	}
+	args = append(args, b.tc.flags...)
+	args = append(args, b.config.Flags...)
+	args = append(args, a.Flags...)
+	args = append(args, objFiles...)
+
+	rpaths := []string{}
+	for _, d := range deps {
+		args = append(args, l.linkPath(d))
+		if d.Type == "shared" && target.OS != types.Windows {
+			rel, _ := filepath.Rel(dir, l.dir(d))
+			if rpath := joinVar(l.origin(), filepath.ToSlash(rel)); !slices.Contains(rpaths, rpath) {
+				rpaths = append(rpaths, rpath)
+			}
+		}
+	}
+	for _, r := range rpaths {
+		args = append(args, "-Wl,-rpath,"+r)
+	}
+
+	for _, l := range a.Libs {
+		args = append(args, "-l"+l)
+	}
+	args = append(args, libs...)
+	if ok := util.RunCmd(b.link(cxx, args...), stdout, stderr, ml, dir); !ok {
+		return false
+	}
+
+	if full != "" {
+		if err := linkVersioned(dir, l.file(a), full, major); err != nil {
+			ml.Logln(log.Error, err.Error())
+			return false
+		}
+	}
+	return true
+}
+
+func (b *CbuildModule) compileArgs(u unit, a Artifact, extra []string) []string {
+	var args []string
+	if u.lang == langCxx {
+		args = slices.Clone(b.tc.cxx)
+	} else {
+		args = slices.Clone(b.tc.cc)
+	}
+
+	args = append(args, "-o", u.obj)
+	if u.dep != "" {
+		args = append(args, "-MMD", "-MF", u.dep)
+	}
+	args = append(args, extra...)
+	args = append(args, b.tc.flags...)
+	args = append(args, b.config.Flags...)
+	args = append(args, a.Flags...)
+
+	switch u.lang {
+	case langC:
+		if b.config.CStd != "" {
+			args = append(args, "-std="+b.config.CStd)
+		}
+		args = append(args, b.config.CFlags...)
+	case langCxx:
+		if b.config.CxxStd != "" {
+			args = append(args, "-std="+b.config.CxxStd)
+		}
+		args = append(args, b.config.CxxFlags...)
+	}
+	return append(args, "-c", u.src)
+}
+
+func (b *CbuildModule) link(cxx bool, args ...string) *exec.Cmd {
+	if cxx {
+		return b.command(append(slices.Clone(b.tc.cxx), args...)...)
+	}
+	return b.command(append(slices.Clone(b.tc.cc), args...)...)
+}
+
+func (b *CbuildModule) arCmd(args ...string) *exec.Cmd {
+	return b.command(append(slices.Clone(b.tc.ar), args...)...)
+}
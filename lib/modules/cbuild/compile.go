@@ -0,0 +1,143 @@
+package cbuild
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+type lang int
+
+const (
+	langNone lang = iota
+	langC
+	langCxx
+	langAsm
+	langAsmCpp
+)
+
+func langOf(file string) lang {
+	switch filepath.Ext(file) {
+	case ".c":
+		return langC
+	case ".cpp", ".cc", ".cxx":
+		return langCxx
+	case ".s":
+		return langAsm
+	case ".S":
+		return langAsmCpp
+	}
+	return langNone
+}
+
+type unit struct {
+	src  string
+	obj  string
+	dep  string
+	lang lang
+	args []string
+}
+
+func newUnit(objDir, srcDir, file string) unit {
+	base := filepath.Join(objDir, file)
+	u := unit{
+		src:  filepath.Join(srcDir, file),
+		obj:  base + ".o",
+		lang: langOf(file),
+	}
+	if u.lang != langAsm {
+		u.dep = base + ".d"
+	}
+	return u
+}
+
+func (u unit) stampPath() string {
+	return u.obj + ".cmd"
+}
+
+func (u unit) stamp() string {
+	return strings.Join(u.args, "\n")
+}
+
+func (u unit) stale() bool {
+	oStat, err := os.Stat(u.obj)
+	if err != nil {
+		return true
+	}
+
+	prev, err := os.ReadFile(u.stampPath())
+	if err != nil || string(prev) != u.stamp() {
+		return true
+	}
+
+	deps := []string{u.src}
+	if u.dep != "" {
+		d, err := parseDepFile(u.dep)
+		if err != nil {
+			return true
+		}
+		deps = append(deps, d...)
+	}
+
+	for _, d := range deps {
+		s, err := os.Stat(d)
+		if err != nil || s.ModTime().After(oStat.ModTime()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u unit) writeStamp() error {
+	return os.WriteFile(u.stampPath(), []byte(u.stamp()), 0644)
+}
+
+type compileResult struct {
+	output string
+	err    error
+}
+
+var compileSlots = make(chan struct{}, runtime.NumCPU())
+
+func (b *CbuildModule) compileAll(units []unit) []compileResult {
+	results := make([]compileResult, len(units))
+	sem := compileSlots
+	if b.config.Jobs > 0 {
+		sem = make(chan struct{}, b.config.Jobs)
+	}
+	wg := sync.WaitGroup{}
+
+	for i, u := range units {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			results[i] = b.compile(u)
+			<-sem
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (b *CbuildModule) compile(u unit) compileResult {
+	err := os.MkdirAll(filepath.Dir(u.obj), 0755)
+	if err != nil {
+		return compileResult{err: err}
+	}
+
+	var out bytes.Buffer
+	cmd := b.command(u.args...)
+	cmd.Dir = b.bc.RelCfgPath()
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err = cmd.Run()
+	if err != nil {
+		return compileResult{output: strings.TrimSpace(out.String()), err: err}
+	}
+	return compileResult{output: strings.TrimSpace(out.String()), err: u.writeStamp()}
+}
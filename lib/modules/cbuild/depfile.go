@@ -0,0 +1,50 @@
+package cbuild
+
+import (
+	"os"
+	"strings"
+)
+
+func parseDepFile(path string) ([]string, error) {
+	by, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := strings.ReplaceAll(string(by), "\\\r\n", " ")
+	s = strings.ReplaceAll(s, "\\\n", " ")
+
+	deps := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		_, rest, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		deps = append(deps, splitDeps(rest)...)
+	}
+	return deps, nil
+}
+
+func splitDeps(s string) []string {
+	deps := []string{}
+	var cur strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == ' ':
+			cur.WriteByte(' ')
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			if cur.Len() > 0 {
+				deps = append(deps, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if cur.Len() > 0 {
+		deps = append(deps, cur.String())
+	}
+	return deps
+}
@@ -0,0 +1,190 @@
+package cbuild
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDepFile(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "single line",
+			src:  "a.o: a.c a.h\n",
+			want: "a.c|a.h",
+		},
+		{
+			name: "continuations",
+			src:  "a.o: a.c \\\n  include/a.h \\\n  include/b.h\n",
+			want: "a.c|include/a.h|include/b.h",
+		},
+		{
+			name: "crlf continuations",
+			src:  "a.o: a.c \\\r\n  a.h\r\n",
+			want: "a.c|a.h",
+		},
+		{
+			name: "escaped spaces",
+			src:  "a.o: my\\ dir/a.c my\\ dir/a\\ b.h\n",
+			want: "my dir/a.c|my dir/a b.h",
+		},
+		{
+			name: "multiple targets",
+			src:  "a.o a.d: a.c a.h\n",
+			want: "a.c|a.h",
+		},
+		{
+			name: "phony targets",
+			src:  "a.o: a.c a.h \\\n b.h\n\na.h:\n\nb.h:\n",
+			want: "a.c|a.h|b.h",
+		},
+		{
+			name: "tabs",
+			src:  "a.o: a.c\ta.h\n",
+			want: "a.c|a.h",
+		},
+		{
+			name: "no dependencies",
+			src:  "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "a.d")
+			if err := os.WriteFile(path, []byte(tt.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			deps, err := parseDepFile(path)
+			if err != nil {
+				t.Fatalf("parseDepFile returned error: %s", err)
+			}
+			if got := strings.Join(deps, "|"); got != tt.want {
+				t.Errorf("deps = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseDepFile(filepath.Join(t.TempDir(), "missing.d")); err == nil {
+		t.Error("parseDepFile of a missing file returned no error")
+	}
+}
+
+func TestUnitStale(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	built := time.Now().Add(-time.Minute)
+
+	setup := func(t *testing.T) (unit, string) {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "src")
+		obj := filepath.Join(dir, "obj")
+		for _, d := range []string{src, obj} {
+			if err := os.MkdirAll(d, 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		u := newUnit(obj, src, "a.c")
+		u.args = []string{"cc", "-c", "a.c"}
+		header := filepath.Join(src, "a.h")
+		files := map[string]string{
+			u.src:  "#include \"a.h\"\n",
+			header: "",
+			u.obj:  "",
+			u.dep:  u.obj + ": " + u.src + " " + header + "\n",
+		}
+		for p, content := range files {
+			if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(p, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.Chtimes(u.obj, built, built); err != nil {
+			t.Fatal(err)
+		}
+		if err := u.writeStamp(); err != nil {
+			t.Fatal(err)
+		}
+		return u, header
+	}
+
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, u *unit, header string)
+		want   bool
+	}{
+		{
+			name:   "up to date",
+			modify: func(t *testing.T, u *unit, header string) {},
+			want:   false,
+		},
+		{
+			name: "missing object",
+			modify: func(t *testing.T, u *unit, header string) {
+				os.Remove(u.obj)
+			},
+			want: true,
+		},
+		{
+			name: "missing stamp",
+			modify: func(t *testing.T, u *unit, header string) {
+				os.Remove(u.stampPath())
+			},
+			want: true,
+		},
+		{
+			name: "changed arguments",
+			modify: func(t *testing.T, u *unit, header string) {
+				u.args = append(u.args, "-O2")
+			},
+			want: true,
+		},
+		{
+			name: "newer source",
+			modify: func(t *testing.T, u *unit, header string) {
+				os.Chtimes(u.src, time.Now(), time.Now())
+			},
+			want: true,
+		},
+		{
+			name: "newer header",
+			modify: func(t *testing.T, u *unit, header string) {
+				os.Chtimes(header, time.Now(), time.Now())
+			},
+			want: true,
+		},
+		{
+			name: "deleted header",
+			modify: func(t *testing.T, u *unit, header string) {
+				os.Remove(header)
+			},
+			want: true,
+		},
+		{
+			name: "missing depfile",
+			modify: func(t *testing.T, u *unit, header string) {
+				os.Remove(u.dep)
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, header := setup(t)
+			tt.modify(t, &u, header)
+			if got := u.stale(); got != tt.want {
+				t.Errorf("stale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
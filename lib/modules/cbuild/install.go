@@ -0,0 +1,175 @@
+package cbuild
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/lspaccatrosi16/lbt/lib/types"
+	"github.com/lspaccatrosi16/lbt/lib/util"
+)
+
+type layout struct {
+	root      string
+	bin       string
+	lib       string
+	include   string
+	pkgconfig string
+	cmake     string
+	install   bool
+	target    types.Target
+	names     types.NamePolicy
+}
+
+func (b *CbuildModule) layout(buildDir string, target types.Target) layout {
+	names := b.bc.NamePolicy(target)
+	if !b.config.Install {
+		return layout{
+			root:      buildDir,
+			bin:       buildDir,
+			lib:       buildDir,
+			include:   filepath.Join(buildDir, "include"),
+			pkgconfig: buildDir,
+			cmake:     buildDir,
+			target:    target,
+			names:     names,
+		}
+	}
+
+	root := filepath.Join(buildDir, target.ExeName(b.bc.Name, false))
+	lib := filepath.Join(root, "lib")
+	return layout{
+		root:      root,
+		bin:       filepath.Join(root, "bin"),
+		lib:       lib,
+		include:   filepath.Join(root, "include"),
+		pkgconfig: filepath.Join(lib, "pkgconfig"),
+		cmake:     filepath.Join(lib, "cmake"),
+		install:   true,
+		target:    target,
+		names:     names,
+	}
+}
+
+func (l layout) file(a Artifact) string {
+	return l.names.FileName(a.Name, types.ArtifactKind(a.Type))
+}
+
+func (l layout) dir(a Artifact) string {
+	if a.Type == "exe" || (a.Type == "shared" && l.target.OS == types.Windows) {
+		return l.bin
+	}
+	return l.lib
+}
+
+func (l layout) path(a Artifact) string {
+	return filepath.Join(l.dir(a), l.file(a))
+}
+
+func (l layout) importLib(a Artifact) string {
+	if a.Type != "shared" || l.target.OS != types.Windows {
+		return ""
+	}
+	return filepath.Join(l.lib, l.names.FileName(a.Name, types.ImportLib))
+}
+
+func (l layout) linkPath(a Artifact) string {
+	if imp := l.importLib(a); imp != "" {
+		return imp
+	}
+	return l.path(a)
+}
+
+func (l layout) linkFlag(a Artifact) string {
+	if l.names.Prefix == "lib" && a.Type != "exe" {
+		return "-l" + a.Name
+	}
+	return "-l:" + filepath.Base(l.linkPath(a))
+}
+
+func (l layout) origin() string {
+	if l.target.OS == types.MacOS {
+		return "@loader_path"
+	}
+	return "$ORIGIN"
+}
+
+func (l layout) cmakeDir(a Artifact) string {
+	if l.install {
+		return filepath.Join(l.cmake, a.Name)
+	}
+	return l.cmake
+}
+
+func (l layout) mkdirs() error {
+	for _, d := range []string{l.bin, l.lib, l.pkgconfig, l.cmake} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *CbuildModule) soVersion(target types.Target) (string, string) {
+	if !b.config.Install || target.OS == types.Windows || target.OS == types.MacOS {
+		return "", ""
+	}
+	v := b.semverVersion()
+	if v == nil {
+		return "", ""
+	}
+	return v.Core(), strconv.FormatUint(v.Major, 10)
+}
+
+func linkVersioned(dir, name, full, major string) error {
+	links := [][2]string{
+		{name + "." + full, name + "." + major},
+		{name + "." + major, name},
+	}
+	for _, l := range links {
+		if l[0] == l[1] {
+			continue
+		}
+		p := filepath.Join(dir, l[1])
+		os.Remove(p)
+		if err := os.Symlink(l[0], p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *CbuildModule) installHeaders(l layout) error {
+	if len(b.config.PublicHeaders) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(l.include, 0755); err != nil {
+		return err
+	}
+
+	for _, h := range b.config.PublicHeaders {
+		matches, err := filepath.Glob(b.path(h))
+		if err != nil {
+			return fmt.Errorf("publicHeaders: %s", err.Error())
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("publicHeaders: %s matched no files", h)
+		}
+
+		for _, m := range matches {
+			s, err := os.Stat(m)
+			if err != nil {
+				return err
+			}
+			dst := l.include
+			if !s.IsDir() {
+				dst = filepath.Join(l.include, filepath.Base(m))
+			}
+			if err := util.Copy(dst, m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package cbuild
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/lspaccatrosi16/lbt/lib/semver"
+)
+
+const pcTemplate = `prefix={{ .PcPrefix }}
+libdir={{ .PcLibDir }}
+{{- if .Include }}
+includedir={{ .PcIncludeDir }}
+{{- end }}
+
+Name: {{ .Name }}
+Description: {{ .Description }}
+Version: {{ .Version }}
+{{- if .Requires }}
+Requires.private: {{ join .Requires ", " }}
+{{- end }}
+{{- if .Include }}
+Cflags: -I${includedir}
+{{- end }}
+Libs: -L${libdir} {{ .LibFlag }}
+{{- if .Libs }}
+Libs.private: {{ join .Libs " " }}
+{{- end }}
+`
+
+const cmakeTemplate = `get_filename_component(_{{ .Name }}_PREFIX "{{ .CMakePrefix }}" ABSOLUTE)
+{{- range .Deps }}
+include("{{ . }}")
+{{- end }}
+
+if(NOT TARGET {{ .Name }}::{{ .Name }})
+  add_library({{ .Name }}::{{ .Name }} {{ .CMakeType }} IMPORTED)
+  set_target_properties({{ .Name }}::{{ .Name }} PROPERTIES
+    IMPORTED_LOCATION "${_{{ .Name }}_PREFIX}/{{ .File }}"
+{{- if .ImpLib }}
+    IMPORTED_IMPLIB "${_{{ .Name }}_PREFIX}/{{ .ImpLib }}"
+{{- end }}
+{{- if .Include }}
+    INTERFACE_INCLUDE_DIRECTORIES "${_{{ .Name }}_PREFIX}/{{ .IncludeDir }}"
+{{- end }}
+{{- if .LinkLibs }}
+    INTERFACE_LINK_LIBRARIES "{{ join .LinkLibs ";" }}"
+{{- end }}
+  )
+endif()
+
+unset(_{{ .Name }}_PREFIX)
+`
+
+const cmakeVersionTemplate = `set(PACKAGE_VERSION "{{ .Version }}")
+
+if(PACKAGE_FIND_VERSION VERSION_GREATER PACKAGE_VERSION)
+  set(PACKAGE_VERSION_COMPATIBLE FALSE)
+else()
+  set(PACKAGE_VERSION_COMPATIBLE TRUE)
+  if(PACKAGE_FIND_VERSION STREQUAL PACKAGE_VERSION)
+    set(PACKAGE_VERSION_EXACT TRUE)
+  endif()
+endif()
+`
+
+var packageTemplates = template.Must(template.New("").Funcs(template.FuncMap{"join": strings.Join}).Parse(`
+{{- define "pc" }}` + pcTemplate + `{{ end }}
+{{- define "cmake" }}` + cmakeTemplate + `{{ end }}
+{{- define "cmakeVersion" }}` + cmakeVersionTemplate + `{{ end }}`))
+
+type packageInfo struct {
+	Name        string
+	Description string
+	Version     string
+	Versioned   bool
+	File        string
+	LibFlag     string
+	ImpLib      string
+	IncludeDir  string
+	CMakeType   string
+	CMakePrefix string
+	Include     bool
+	Deps        []string
+	Requires    []string
+	Libs        []string
+	LinkLibs    []string
+
+	PcPrefix     string
+	PcLibDir     string
+	PcIncludeDir string
+}
+
+func (b *CbuildModule) packageInfo(l layout, a Artifact) packageInfo {
+	rel := func(from, to string) string {
+		r, _ := filepath.Rel(from, to)
+		return filepath.ToSlash(r)
+	}
+
+	info := packageInfo{
+		Name:         a.Name,
+		Description:  b.config.Description,
+		Version:      "0.0.0",
+		File:         rel(l.root, l.path(a)),
+		LibFlag:      l.linkFlag(a),
+		IncludeDir:   rel(l.root, l.include),
+		CMakeType:    "STATIC",
+		CMakePrefix:  joinVar("${CMAKE_CURRENT_LIST_DIR}", rel(l.cmakeDir(a), l.root)),
+		Include:      len(b.config.PublicHeaders) > 0,
+		PcPrefix:     joinVar("${pcfiledir}", rel(l.pkgconfig, l.root)),
+		PcLibDir:     joinVar("${prefix}", rel(l.root, l.lib)),
+		PcIncludeDir: joinVar("${prefix}", rel(l.root, l.include)),
+	}
+	if info.Description == "" {
+		info.Description = a.Name
+	}
+	if v := b.semverVersion(); v != nil {
+		info.Version = v.Core()
+		info.Versioned = true
+	}
+	info.Requires = append(slices.Clone(a.Deps), b.config.PkgConfig...)
+	for _, l := range slices.Concat(a.Libs, b.config.Libs) {
+		info.Libs = append(info.Libs, "-l"+l)
+	}
+
+	if a.Type == "shared" {
+		info.CMakeType = "SHARED"
+		if imp := l.importLib(a); imp != "" {
+			info.ImpLib = rel(l.root, imp)
+		}
+		return info
+	}
+
+	for _, d := range a.Deps {
+		dep := Artifact{Name: d}
+		cfg := filepath.Join(l.cmakeDir(dep), d+"Config.cmake")
+		info.Deps = append(info.Deps, joinVar("${CMAKE_CURRENT_LIST_DIR}", rel(l.cmakeDir(a), cfg)))
+		info.LinkLibs = append(info.LinkLibs, d+"::"+d)
+	}
+	info.LinkLibs = append(info.LinkLibs, info.Libs...)
+	return info
+}
+
+func (b *CbuildModule) writePackageFiles(l layout, a Artifact) error {
+	info := b.packageInfo(l, a)
+
+	files := map[string]string{}
+	if b.config.PkgConfigFile {
+		files["pc"] = filepath.Join(l.pkgconfig, a.Name+".pc")
+	}
+	if b.config.CMakeFile {
+		dir := l.cmakeDir(a)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		files["cmake"] = filepath.Join(dir, a.Name+"Config.cmake")
+		if info.Versioned {
+			files["cmakeVersion"] = filepath.Join(dir, a.Name+"ConfigVersion.cmake")
+		}
+	}
+
+	for tmpl, path := range files {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		err = packageTemplates.ExecuteTemplate(f, tmpl, info)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// semverVersion returns the build's version when the version type is semver,
+// and nil otherwise, since other version types cannot be compared by consumers.
+func (b *CbuildModule) semverVersion() *semver.Version {
+	if b.bc.Context == nil || b.bc.Version.VtS != "semver" || b.bc.Context.Version == "" {
+		return nil
+	}
+	v, err := semver.Parse(b.bc.Context.Version)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func joinVar(v, rel string) string {
+	if rel == "." {
+		return v
+	}
+	return v + "/" + rel
+}
@@ -0,0 +1,291 @@
+package cbuild
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type pkg struct {
+	name    string
+	version string
+	cflags  []string
+	libs    []string
+}
+
+func (b *CbuildModule) resolvePackages() ([]pkg, error) {
+	pkgs := []pkg{}
+	for _, name := range b.config.PkgConfig {
+		var p pkg
+		var err error
+		if len(b.tc.pkgConfigPath) > 0 {
+			p, err = b.readPackage(name)
+		} else {
+			p, err = b.queryPackage(name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pkgconfig %s: %s", name, err.Error())
+		}
+		pkgs = append(pkgs, p)
+	}
+	return pkgs, nil
+}
+
+func (b *CbuildModule) queryPackage(name string) (pkg, error) {
+	p := pkg{name: name}
+	query := func(flag string) (string, error) {
+		var out, stderr bytes.Buffer
+		cmd := b.command("pkg-config", flag, name)
+		cmd.Stdout = &out
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if stderr.Len() > 0 {
+				return "", fmt.Errorf("%s", strings.TrimSpace(stderr.String()))
+			}
+			return "", err
+		}
+		return strings.TrimSpace(out.String()), nil
+	}
+
+	var err error
+	if p.version, err = query("--modversion"); err != nil {
+		return p, err
+	}
+	cflags, err := query("--cflags")
+	if err != nil {
+		return p, err
+	}
+	libs, err := query("--libs")
+	if err != nil {
+		return p, err
+	}
+	p.cflags = splitFlags(cflags)
+	p.libs = splitFlags(libs)
+	return p, nil
+}
+
+type pcFile struct {
+	version         string
+	cflags          []string
+	libs            []string
+	requires        []string
+	requiresPrivate []string
+}
+
+func (b *CbuildModule) readPackage(name string) (pkg, error) {
+	seen := map[string]bool{}
+	var cflags, libs []string
+
+	var walk func(name string, private bool) (*pcFile, error)
+	walk = func(name string, private bool) (*pcFile, error) {
+		if seen[name] {
+			return nil, nil
+		}
+		seen[name] = true
+
+		pc, err := b.findPCFile(name)
+		if err != nil {
+			return nil, err
+		}
+		cflags = append(cflags, pc.cflags...)
+		if !private {
+			libs = append(libs, pc.libs...)
+		}
+		for _, r := range pc.requires {
+			if _, err := walk(r, private); err != nil {
+				return nil, err
+			}
+		}
+		for _, r := range pc.requiresPrivate {
+			if _, err := walk(r, true); err != nil {
+				return nil, err
+			}
+		}
+		return pc, nil
+	}
+
+	pc, err := walk(name, false)
+	if err != nil {
+		return pkg{}, err
+	}
+	return pkg{
+		name:    name,
+		version: pc.version,
+		cflags:  b.sysrootFlags(dedupe(cflags, false)),
+		libs:    b.sysrootFlags(dedupe(libs, true)),
+	}, nil
+}
+
+var systemDirs = map[string][]string{
+	"-I": {"/usr/include"},
+	"-L": {"/usr/lib", "/usr/lib64", "/lib", "/lib64"},
+}
+
+func (b *CbuildModule) sysrootFlags(flags []string) []string {
+	out := []string{}
+	for _, f := range flags {
+		if len(f) > 2 {
+			if dirs, ok := systemDirs[f[:2]]; ok {
+				if slices.Contains(dirs, filepath.Clean(f[2:])) {
+					continue
+				}
+				if b.tc.sysroot != "" && filepath.IsAbs(f[2:]) {
+					f = f[:2] + filepath.Join(b.tc.sysroot, f[2:])
+				}
+			}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func (b *CbuildModule) findPCFile(name string) (*pcFile, error) {
+	for _, dir := range b.tc.pkgConfigPath {
+		path := filepath.Join(dir, name+".pc")
+		if _, err := os.Stat(path); err == nil {
+			return parsePCFile(path)
+		}
+	}
+	return nil, fmt.Errorf("%s.pc not found in %s", name, strings.Join(b.tc.pkgConfigPath, ", "))
+}
+
+func parsePCFile(path string) (*pcFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := map[string]string{"pcfiledir": filepath.Dir(path)}
+	pc := &pcFile{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:i])
+		value := expandPCVars(strings.TrimSpace(line[i+1:]), vars)
+		if line[i] == '=' {
+			vars[key] = value
+			continue
+		}
+
+		switch key {
+		case "Version":
+			pc.version = value
+		case "Cflags":
+			pc.cflags = splitFlags(value)
+		case "Libs":
+			pc.libs = splitFlags(value)
+		case "Requires":
+			pc.requires = parseRequires(value)
+		case "Requires.private":
+			pc.requiresPrivate = parseRequires(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+func expandPCVars(s string, vars map[string]string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '$' && i+1 < len(s) && s[i+1] == '$' {
+			out.WriteByte('$')
+			i++
+			continue
+		}
+		if s[i] == '$' && i+1 < len(s) && s[i+1] == '{' {
+			if end := strings.IndexByte(s[i:], '}'); end > 0 {
+				out.WriteString(vars[s[i+2:i+end]])
+				i += end
+				continue
+			}
+		}
+		out.WriteByte(s[i])
+	}
+	return out.String()
+}
+
+func parseRequires(s string) []string {
+	names := []string{}
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "=", "<", ">", "<=", ">=", "!=":
+			i++
+		default:
+			names = append(names, fields[i])
+		}
+	}
+	return names
+}
+
+func splitFlags(s string) []string {
+	flags := []string{}
+	var cur strings.Builder
+	var quote byte
+	inFlag := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteByte(c)
+		case c == '\'' || c == '"':
+			quote = c
+			inFlag = true
+		case c == '\\' && i+1 < len(s):
+			cur.WriteByte(s[i+1])
+			inFlag = true
+			i++
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inFlag {
+				flags = append(flags, cur.String())
+				cur.Reset()
+				inFlag = false
+			}
+		default:
+			cur.WriteByte(c)
+			inFlag = true
+		}
+	}
+	if inFlag {
+		flags = append(flags, cur.String())
+	}
+	return flags
+}
+
+func dedupe(flags []string, keepLast bool) []string {
+	out := []string{}
+	if keepLast {
+		for i := len(flags) - 1; i >= 0; i-- {
+			if !slices.Contains(out, flags[i]) {
+				out = append(out, flags[i])
+			}
+		}
+		slices.Reverse(out)
+		return out
+	}
+	for _, f := range flags {
+		if !slices.Contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
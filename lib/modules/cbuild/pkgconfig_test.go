@@ -0,0 +1,160 @@
+package cbuild
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitFlags(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"", ""},
+		{"-I/usr/include/foo -lfoo", "-I/usr/include/foo|-lfoo"},
+		{"  -lfoo\t-lbar\n", "-lfoo|-lbar"},
+		{`-DNAME="a b" -lfoo`, "-DNAME=a b|-lfoo"},
+		{`'-I/opt/my dir' -lfoo`, "-I/opt/my dir|-lfoo"},
+		{`-I/opt/my\ dir -lfoo`, "-I/opt/my dir|-lfoo"},
+		{`-DQ=\"x\"`, `-DQ="x"`},
+		{`"it's" 'say "hi"'`, `it's|say "hi"`},
+		{`-DEMPTY="" ""`, "-DEMPTY=|"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			if got := strings.Join(splitFlags(tt.src), "|"); got != tt.want {
+				t.Errorf("splitFlags(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func writePC(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name+".pc")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParsePCFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writePC(t, dir, "foo", `# foo library
+prefix=/opt/foo
+exec_prefix=${prefix}
+libdir=${exec_prefix}/lib
+includedir=${prefix}/include
+datadir=${pcfiledir}/../share
+
+Name: foo
+Version: 1.2.3
+Description: costs $$5
+Requires: bar >= 1.0, baz
+Requires.private: qux = 2
+Cflags: -I${includedir} -DDATA=${datadir} # trailing comment
+Libs: -L${libdir} -lfoo ${undefined}
+`)
+
+	pc, err := parsePCFile(path)
+	if err != nil {
+		t.Fatalf("parsePCFile returned error: %s", err)
+	}
+
+	checks := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"version", pc.version, "1.2.3"},
+		{"cflags", strings.Join(pc.cflags, "|"), "-I/opt/foo/include|-DDATA=" + dir + "/../share"},
+		{"libs", strings.Join(pc.libs, "|"), "-L/opt/foo/lib|-lfoo"},
+		{"requires", strings.Join(pc.requires, "|"), "bar|baz"},
+		{"requires.private", strings.Join(pc.requiresPrivate, "|"), "qux"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+
+	if _, err := parsePCFile(filepath.Join(dir, "missing.pc")); err == nil {
+		t.Error("parsePCFile of a missing file returned no error")
+	}
+}
+
+func TestExpandPCVars(t *testing.T) {
+	vars := map[string]string{"prefix": "/usr", "name": "foo"}
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"${prefix}/lib", "/usr/lib"},
+		{"${prefix}/include/${name}", "/usr/include/foo"},
+		{"$${prefix}", "${prefix}"},
+		{"${missing}x", "x"},
+		{"${unterminated", "${unterminated"},
+		{"$5", "$5"},
+	}
+	for _, tt := range tests {
+		if got := expandPCVars(tt.src, vars); got != tt.want {
+			t.Errorf("expandPCVars(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseRequires(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"", ""},
+		{"foo", "foo"},
+		{"foo bar", "foo|bar"},
+		{"foo, bar", "foo|bar"},
+		{"foo >= 1.0, bar < 2 baz", "foo|bar|baz"},
+		{"foo != 1.0,bar=2", "foo|bar=2"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(parseRequires(tt.src), "|"); got != tt.want {
+			t.Errorf("parseRequires(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestReadPackage(t *testing.T) {
+	dir := t.TempDir()
+	writePC(t, dir, "app", "Version: 2.0\nRequires: net\nRequires.private: zlib\nCflags: -I/opt/app/include\nLibs: -L/opt/lib -lapp\n")
+	writePC(t, dir, "net", "Version: 1.0\nRequires: zlib\nCflags: -I/opt/net/include -I/usr/include\nLibs: -L/opt/lib -lnet -lz\n")
+	writePC(t, dir, "zlib", "Version: 1.3\nCflags: -I/opt/zlib/include\nLibs: -L/usr/lib -lz\n")
+
+	b := &CbuildModule{tc: toolchain{pkgConfigPath: []string{filepath.Join(dir, "missing"), dir}}}
+	p, err := b.readPackage("app")
+	if err != nil {
+		t.Fatalf("readPackage returned error: %s", err)
+	}
+	if p.version != "2.0" {
+		t.Errorf("version = %q, want 2.0", p.version)
+	}
+	if got, want := strings.Join(p.cflags, " "), "-I/opt/app/include -I/opt/net/include -I/opt/zlib/include"; got != want {
+		t.Errorf("cflags = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(p.libs, " "), "-lapp -L/opt/lib -lnet -lz"; got != want {
+		t.Errorf("libs = %q, want %q", got, want)
+	}
+
+	b.tc.sysroot = "/sysroot"
+	p, err = b.readPackage("zlib")
+	if err != nil {
+		t.Fatalf("readPackage returned error: %s", err)
+	}
+	if got, want := strings.Join(p.cflags, " "), "-I/sysroot/opt/zlib/include"; got != want {
+		t.Errorf("cflags with sysroot = %q, want %q", got, want)
+	}
+
+	if _, err := b.readPackage("missing"); err == nil {
+		t.Error("readPackage of a missing package returned no error")
+	}
+}
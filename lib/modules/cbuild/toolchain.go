@@ -0,0 +1,128 @@
+package cbuild
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/lspaccatrosi16/lbt/lib/types"
+)
+
+type Toolchain struct {
+	Compiler string   `yaml:"compiler"`
+	Cxx      string   `yaml:"cxx"`
+	Ar       string   `yaml:"ar"`
+	Sysroot  string   `yaml:"sysroot"`
+	Flags    []string `yaml:"flags"`
+	PkgPath  []string `yaml:"pkgconfigPath"`
+}
+
+type toolchain struct {
+	cc    []string
+	cxx   []string
+	ar    []string
+	flags []string
+
+	sysroot       string
+	pkgConfigPath []string
+}
+
+func (b *CbuildModule) resolveToolchain(target types.Target) (toolchain, error) {
+	tc := Toolchain{Compiler: b.config.Compiler, Cxx: b.config.Cxx, Ar: "ar", PkgPath: b.config.PkgConfigPath}
+
+	patterns := []string{}
+	for p := range b.config.Toolchains {
+		if target.Matches(p) {
+			patterns = append(patterns, p)
+		}
+	}
+	types.SortPatterns(patterns)
+
+	host := target == types.NoTarget || (target.CmpRuntimeOS() && target.CmpRuntimeArch())
+	if len(patterns) == 0 && b.config.Zig {
+		triple, err := target.Triple()
+		if err != nil && !host {
+			return toolchain{}, err
+		}
+		res := toolchain{cc: []string{"zig", "cc"}, cxx: []string{"zig", "c++"}, ar: []string{"zig", "ar"}}
+		if !host {
+			res.cc = append(res.cc, "-target", triple)
+			res.cxx = append(res.cxx, "-target", triple)
+		}
+		res.pkgConfigPath = b.pkgConfigPath(tc.PkgPath)
+		return res, nil
+	}
+
+	if len(patterns) == 0 && !host {
+		return toolchain{}, fmt.Errorf("cbuild cannot cross-compile for %s: add a toolchain for it, or enable zig", target.String())
+	}
+
+	for _, p := range patterns {
+		t := b.config.Toolchains[p]
+		if t.Compiler != "" {
+			tc.Compiler = t.Compiler
+			tc.Cxx = t.Cxx
+		}
+		if t.Cxx != "" {
+			tc.Cxx = t.Cxx
+		}
+		if t.Ar != "" {
+			tc.Ar = t.Ar
+		}
+		if t.Sysroot != "" {
+			tc.Sysroot = t.Sysroot
+		}
+		if len(t.PkgPath) > 0 {
+			tc.PkgPath = t.PkgPath
+		}
+		tc.Flags = append(tc.Flags, t.Flags...)
+	}
+
+	if tc.Compiler == "" {
+		return toolchain{}, fmt.Errorf("cbuild has no compiler for %s", target.String())
+	}
+
+	if tc.Cxx == "" {
+		tc.Cxx = cxxFor(tc.Compiler)
+	}
+
+	res := toolchain{cc: []string{tc.Compiler}, ar: []string{tc.Ar}, flags: tc.Flags, pkgConfigPath: b.pkgConfigPath(tc.PkgPath)}
+	if tc.Cxx != "" {
+		res.cxx = []string{tc.Cxx}
+	}
+	if tc.Sysroot != "" {
+		res.sysroot = b.path(tc.Sysroot)
+		res.flags = append(res.flags, "--sysroot="+res.sysroot)
+	}
+	return res, nil
+}
+
+func (b *CbuildModule) pkgConfigPath(paths []string) []string {
+	res := []string{}
+	for _, p := range paths {
+		res = append(res, b.path(p))
+	}
+	return res
+}
+
+func (b *CbuildModule) path(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return b.bc.RelCfgPath(p)
+}
+
+func cxxFor(compiler string) string {
+	dir, base := filepath.Split(compiler)
+	switch {
+	case strings.HasSuffix(base, "clang"):
+		base += "++"
+	case strings.HasSuffix(base, "gcc"):
+		base = strings.TrimSuffix(base, "gcc") + "g++"
+	case strings.HasSuffix(base, "cc"):
+		base = strings.TrimSuffix(base, "cc") + "c++"
+	default:
+		return ""
+	}
+	return dir + base
+}
@@ -22,7 +22,7 @@ func (c *CleanupModule) Configure(config *types.BuildConfig) error {
 
 func (c *CleanupModule) RunModule(modLogger *log.Logger, _ types.Target) bool {
 	ml := modLogger.ChildLogger("cleanup")
-	err := os.RemoveAll(types.NoTarget.TempDir())
+	err := os.RemoveAll(c.bc.TempDir(types.NoTarget))
 	if err != nil {
 		ml.Logln(log.Error, err.Error())
 		return false
@@ -10,6 +10,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
@@ -39,9 +40,9 @@ type CompressModule struct {
 	config *ModConfig
 }
 type ModConfig struct {
-	Module  string `yaml:"module" validate:"required"`
-	Fts     string `yaml:"format" validate:"required"`
-	Sformat compressionFormat
+	Module  string            `yaml:"module" validate:"required"`
+	Fts     string            `yaml:"format" validate:"required,oneof=tar.gz zip"`
+	Sformat compressionFormat `yaml:"-"`
 }
 
 func (s *CompressModule) Configure(config *types.BuildConfig) error {
@@ -51,14 +52,6 @@ func (s *CompressModule) Configure(config *types.BuildConfig) error {
 		return err
 	}
 
-	if cfg.Module == "" {
-		return fmt.Errorf("compress module requires input module")
-	}
-
-	if cfg.Fts == "" {
-		return fmt.Errorf("compress module requires format")
-	}
-
 	ft, err := parseCompressionFormat(cfg.Fts)
 	if err != nil {
 		return err
@@ -72,13 +65,13 @@ func (s *CompressModule) Configure(config *types.BuildConfig) error {
 func (s *CompressModule) RunModule(modLogger *log.Logger, target types.Target) bool {
 	ml := modLogger.ChildLogger("compress")
 
-	objDir := filepath.Join(target.TempDir(), s.config.Module)
+	objDir := filepath.Join(s.bc.TempDir(target), s.config.Module)
 	dE, err := os.ReadDir(objDir)
 	if err != nil {
 		log.Logln(log.Error, err.Error())
 		return false
 	}
-	outDir := filepath.Join(target.TempDir(), "compress")
+	outDir := filepath.Join(s.bc.TempDir(target), "compress")
 	err = os.MkdirAll(outDir, 0755)
 	if err != nil {
 		log.Logln(log.Error, err.Error())
@@ -147,7 +140,16 @@ func tarGzCompress(src string, buf io.Writer) error {
 	tw := tar.NewWriter(zr)
 
 	err := filepath.Walk(src, func(file string, fi fs.FileInfo, _ error) error {
-		header, err := tar.FileInfoHeader(fi, "")
+		link := ""
+		if fi.Mode()&fs.ModeSymlink != 0 {
+			var err error
+			link, err = os.Readlink(file)
+			if err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(fi, link)
 		if err != nil {
 			return err
 		}
@@ -167,7 +169,7 @@ func tarGzCompress(src string, buf io.Writer) error {
 			return err
 		}
 
-		if !fi.IsDir() {
+		if fi.Mode().IsRegular() {
 			data, err := os.Open(file)
 			if err != nil {
 				return err
@@ -216,7 +218,13 @@ func zipCompress(src string, buf io.Writer) error {
 			return err
 		}
 
-		if !fi.IsDir() {
+		if fi.Mode()&fs.ModeSymlink != 0 {
+			link, err := os.Readlink(file)
+			if err != nil {
+				return err
+			}
+			io.WriteString(f, link)
+		} else if !fi.IsDir() {
 			data, err := os.Open(file)
 			if err != nil {
 				return err
@@ -241,5 +249,13 @@ func (c *CompressModule) TargetAgnostic() bool {
 }
 
 func (*CompressModule) RunOnCached() bool {
-	return false 
+	return false
+}
+
+func (*CompressModule) ConfigType() reflect.Type {
+	return reflect.TypeFor[ModConfig]()
+}
+
+func (s *CompressModule) ResolvedConfig() any {
+	return s.config
 }
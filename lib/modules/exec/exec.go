@@ -0,0 +1,239 @@
+package exec
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lspaccatrosi16/lbt/lib/log"
+	"github.com/lspaccatrosi16/lbt/lib/types"
+	"github.com/lspaccatrosi16/lbt/lib/util"
+)
+
+type ExecModule struct {
+	bc     *types.BuildConfig
+	config *ModConfig
+	env    []string
+}
+
+type Command struct {
+	Name    string   `yaml:"name" validate:"required"`
+	Argv    []string `yaml:"argv"`
+	Shell   string   `yaml:"shell"`
+	Dir     string   `yaml:"dir"`
+	Inputs  []string `yaml:"inputs"`
+	Outputs []string `yaml:"outputs"`
+}
+
+type ModConfig struct {
+	Commands       []Command `yaml:"commands" validate:"required"`
+	TargetAgnostic bool      `yaml:"targetAgnostic"`
+	Requires       []string  `yaml:"requires"`
+}
+
+func (e *ExecModule) Configure(config *types.BuildConfig) error {
+	e.bc = config
+	cfg, err := types.GetModConfig[ModConfig](config, "exec")
+	if err != nil {
+		return err
+	}
+
+	for _, c := range cfg.Commands {
+		if len(c.Argv) == 0 && c.Shell == "" {
+			return fmt.Errorf("exec command %s requires either \"argv\" or \"shell\" to be set", c.Name)
+		} else if len(c.Argv) > 0 && c.Shell != "" {
+			return fmt.Errorf("exec command %s requires only 1 of \"argv\" and \"shell\" to be set", c.Name)
+		}
+	}
+
+	e.env, err = config.Environ("exec")
+	if err != nil {
+		return fmt.Errorf("module exec: %s", err.Error())
+	}
+
+	e.config = cfg
+	return nil
+}
+
+var dirLocks sync.Map
+
+func (e *ExecModule) RunModule(modLogger *log.Logger, target types.Target) bool {
+	ml := modLogger.ChildLogger("exec")
+	outDir := filepath.Join(e.bc.TempDir(target), "exec")
+
+	if !e.config.TargetAgnostic {
+		return e.run(ml, outDir)
+	}
+
+	sharedDir := filepath.Join(e.bc.TempDir(types.NoTarget), "exec")
+	ok := e.bc.RunOnce(sharedDir, func() bool {
+		shared := &ExecModule{}
+		err := shared.Configure(e.bc.ForTarget(types.NoTarget))
+		if err != nil {
+			ml.Logln(log.Error, err.Error())
+			return false
+		}
+		return shared.run(ml, sharedDir)
+	})
+	if !ok {
+		ml.Logln(log.Error, "target agnostic commands failed")
+		return false
+	}
+
+	err := os.MkdirAll(outDir, 0755)
+	if err != nil {
+		ml.Logln(log.Error, err.Error())
+		return false
+	}
+	err = util.Copy(outDir, sharedDir)
+	if err != nil {
+		ml.Logln(log.Error, err.Error())
+		return false
+	}
+	return true
+}
+
+func (e *ExecModule) run(ml *log.Logger, outDir string) bool {
+	err := os.MkdirAll(outDir, 0755)
+	if err != nil {
+		ml.Logln(log.Error, err.Error())
+		return false
+	}
+
+	env := append(append([]string{}, e.env...), e.bc.ModuleVars("exec")...)
+
+	mu, _ := dirLocks.LoadOrStore(e.bc.RelCfgPath(), &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	for _, c := range e.config.Commands {
+		dir := e.bc.RelCfgPath(c.Dir)
+		fresh := false
+		if e.config.TargetAgnostic {
+			fresh, err = upToDate(dir, c)
+			if err != nil {
+				ml.Logln(log.Error, err.Error())
+				return false
+			}
+		}
+
+		if fresh {
+			ml.Logf(log.Info, "%s is up to date", c.Name)
+		} else {
+			var cmd *exec.Cmd
+			if c.Shell != "" {
+				cmd = e.bc.Command("sh", "-c", c.Shell)
+			} else {
+				cmd = e.bc.Command(c.Argv[0], c.Argv[1:]...)
+			}
+			cmd.Env = env
+			ml.Logf(log.Info, "command: %s", strings.Join(cmd.Args, " "))
+
+			var stdout, stderr bytes.Buffer
+			if ok := util.RunCmd(cmd, stdout, stderr, ml, dir); !ok {
+				return false
+			}
+		}
+
+		for _, o := range c.Outputs {
+			src := filepath.Join(dir, o)
+			dst := filepath.Join(outDir, filepath.Base(o))
+			if s, err := os.Stat(src); err == nil && s.IsDir() {
+				err = os.MkdirAll(dst, 0755)
+				if err != nil {
+					ml.Logln(log.Error, err.Error())
+					return false
+				}
+			}
+			err = util.Copy(dst, src)
+			if err != nil {
+				ml.Logf(log.Error, "command %s did not produce output %s: %s", c.Name, o, err.Error())
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func upToDate(dir string, c Command) (bool, error) {
+	if len(c.Inputs) == 0 || len(c.Outputs) == 0 {
+		return false, nil
+	}
+
+	var oldestOut time.Time
+	for _, o := range c.Outputs {
+		s, err := os.Stat(filepath.Join(dir, o))
+		if err != nil {
+			return false, nil
+		}
+		if oldestOut.IsZero() || s.ModTime().Before(oldestOut) {
+			oldestOut = s.ModTime()
+		}
+	}
+
+	for _, in := range c.Inputs {
+		matches, err := filepath.Glob(filepath.Join(dir, in))
+		if err != nil {
+			return false, fmt.Errorf("invalid input pattern %s: %s", in, err.Error())
+		}
+		for _, m := range matches {
+			newer := false
+			err = filepath.WalkDir(m, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && info.ModTime().After(oldestOut) {
+					newer = true
+					return filepath.SkipAll
+				}
+				return nil
+			})
+			if err != nil {
+				return false, err
+			}
+			if newer {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func (e *ExecModule) Name() string {
+	return "exec"
+}
+
+func (e *ExecModule) Requires() []string {
+	return e.config.Requires
+}
+
+func (e *ExecModule) OnFail() error {
+	return nil
+}
+
+func (e *ExecModule) TargetAgnostic() bool {
+	return e.config != nil && e.config.TargetAgnostic
+}
+
+func (*ExecModule) RunOnCached() bool {
+	return false
+}
+
+func (*ExecModule) ConfigType() reflect.Type {
+	return reflect.TypeFor[ModConfig]()
+}
+
+func (e *ExecModule) ResolvedConfig() any {
+	return e.config
+}
@@ -3,9 +3,10 @@ package gobuild
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"reflect"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
 	"github.com/lspaccatrosi16/lbt/lib/types"
@@ -14,6 +15,7 @@ import (
 type GobuildModule struct {
 	bc     *types.BuildConfig
 	config *ModConfig
+	env    []string
 }
 
 type Command struct {
@@ -34,6 +36,11 @@ func (b *GobuildModule) Configure(config *types.BuildConfig) error {
 	if err != nil {
 		return err
 	}
+
+	b.env, err = config.Environ("gobuild")
+	if err != nil {
+		return fmt.Errorf("module gobuild: %s", err.Error())
+	}
 	b.config = cfg
 	return nil
 }
@@ -65,17 +72,18 @@ func (b *GobuildModule) buildCommandTarget(ml *log.Logger, cmd Command, target t
 		return err
 	}
 
-	outPath := filepath.Join(target.TempDir(), "gobuild", target.ExeName(cmd.Name, true))
+	outPath := filepath.Join(b.bc.TempDir(target), "gobuild", target.ExeName(cmd.Name, true))
 	args := []string{"build", "-o", outPath}
 	if b.config.Ldflags != "" {
 		args = append(args, "-ldflags", b.config.Ldflags)
 	}
 	args = append(args, cmdPath)
-	eCmd := exec.Command("go", args...)
-	eCmd.Env = os.Environ()
+	eCmd := b.bc.Command("go", args...)
+	eCmd.Env = b.env
 
-	eCmd.Env = append(eCmd.Env, "GOOS="+string(target.OS))
-	eCmd.Env = append(eCmd.Env, "GOARCH="+string(target.Arch))
+	goos, goarch, _ := target.Names(types.ToolchainGo)
+	eCmd.Env = append(eCmd.Env, "GOOS="+goos)
+	eCmd.Env = append(eCmd.Env, "GOARCH="+goarch)
 	if b.config.DisableCgo {
 		eCmd.Env = append(eCmd.Env, "CGO_ENABLED=0")
 	}
@@ -123,3 +131,15 @@ func (b *GobuildModule) TargetAgnostic() bool {
 func (*GobuildModule) RunOnCached() bool {
 	return false
 }
+
+func (*GobuildModule) ConfigType() reflect.Type {
+	return reflect.TypeFor[ModConfig]()
+}
+
+func (b *GobuildModule) ResolvedConfig() any {
+	return b.config
+}
+
+func (*GobuildModule) SupportsTarget(t types.Target) bool {
+	return t.Supports(types.ToolchainGo)
+}
@@ -2,9 +2,11 @@ package javabuild
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
 	"github.com/lspaccatrosi16/lbt/lib/types"
@@ -14,6 +16,7 @@ import (
 type JavabuildModule struct {
 	bc     *types.BuildConfig
 	config *ModConfig
+	env    []string
 }
 
 type ModConfig struct {
@@ -27,6 +30,11 @@ func (b *JavabuildModule) Configure(config *types.BuildConfig) error {
 	if err != nil {
 		return err
 	}
+
+	b.env, err = config.Environ("javabuild")
+	if err != nil {
+		return fmt.Errorf("module javabuild: %s", err.Error())
+	}
 	b.config = cfg
 	return nil
 }
@@ -34,10 +42,6 @@ func (b *JavabuildModule) Configure(config *types.BuildConfig) error {
 func (b *JavabuildModule) RunModule(modLogger *log.Logger, target types.Target) bool {
 	ml := modLogger.ChildLogger("javabuild")
 
-	if target.OS != types.JVM {
-		return true
-	}
-
 	ml.Logln(log.Info, "Building Java Classes")
 
 	files, err := util.ScanDir(b.bc.RelCfgPath(), ".java")
@@ -49,7 +53,7 @@ func (b *JavabuildModule) RunModule(modLogger *log.Logger, target types.Target)
 
 	ml.Logln(log.Info, "Include files", files)
 
-	od := filepath.Join(target.TempDir(), "javabuild")
+	od := filepath.Join(b.bc.TempDir(target), "javabuild")
 	odt := filepath.Join(od, "build")
 
 	args := []string{"-d", odt}
@@ -62,7 +66,7 @@ func (b *JavabuildModule) RunModule(modLogger *log.Logger, target types.Target)
 
 	var stdout, stderr bytes.Buffer
 
-	if res := util.RunCmd(exec.Command("javac", args...), stdout, stderr, ml, b.bc.RelCfgPath()); !res {
+	if res := util.RunCmd(b.command("javac", args...), stdout, stderr, ml, b.bc.RelCfgPath()); !res {
 		return false
 	}
 
@@ -89,12 +93,12 @@ func (b *JavabuildModule) RunModule(modLogger *log.Logger, target types.Target)
 	ml.Logln(log.Info, "Resolving Dependencies")
 
 	for _, dep := range b.config.Dependencies {
-		if res := util.RunCmd(exec.Command("jar", "xf", filepath.Join(b.bc.RelCfgPath(), dep)), stdout, stderr, ml, odt); !res {
+		if res := util.RunCmd(b.command("jar", "xf", filepath.Join(b.bc.RelCfgPath(), dep)), stdout, stderr, ml, odt); !res {
 			return false
 		}
 	}
 
-	util.RunCmd(exec.Command("rm", "-rf", "META-INF"), stdout, stderr, ml, odt)
+	util.RunCmd(b.bc.Command("rm", "-rf", "META-INF"), stdout, stderr, ml, odt)
 
 	ml.Logln(log.Info, "Bundling Jar")
 	args = []string{"--create"}
@@ -116,11 +120,11 @@ func (b *JavabuildModule) RunModule(modLogger *log.Logger, target types.Target)
 	args = append(args, "-f", outPath, "-m", mPath)
 	args = append(args, classes...)
 
-	if res := util.RunCmd(exec.Command("jar", args...), stdout, stderr, ml, odt); !res {
+	if res := util.RunCmd(b.command("jar", args...), stdout, stderr, ml, odt); !res {
 		return false
 	}
 
-	if res := util.RunCmd(exec.Command("rm", "-r", odt), stdout, stderr, ml, od); !res {
+	if res := util.RunCmd(b.bc.Command("rm", "-r", odt), stdout, stderr, ml, od); !res {
 		return false
 	}
 
@@ -139,6 +143,10 @@ func (b *JavabuildModule) OnFail() error {
 	return nil
 }
 
+func (*JavabuildModule) SupportsTarget(t types.Target) bool {
+	return t.OS == types.JVM
+}
+
 func (b *JavabuildModule) TargetAgnostic() bool {
 	return true
 }
@@ -146,3 +154,17 @@ func (b *JavabuildModule) TargetAgnostic() bool {
 func (*JavabuildModule) RunOnCached() bool {
 	return false
 }
+
+func (*JavabuildModule) ConfigType() reflect.Type {
+	return reflect.TypeFor[ModConfig]()
+}
+
+func (b *JavabuildModule) ResolvedConfig() any {
+	return b.config
+}
+
+func (b *JavabuildModule) command(name string, args ...string) *exec.Cmd {
+	cmd := b.bc.Command(name, args...)
+	cmd.Env = b.env
+	return cmd
+}
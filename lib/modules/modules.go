@@ -1,13 +1,18 @@
 package modules
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/lspaccatrosi16/lbt/lib/modules/cbuild"
 	"github.com/lspaccatrosi16/lbt/lib/modules/cleanup"
 	"github.com/lspaccatrosi16/lbt/lib/modules/compress"
+	"github.com/lspaccatrosi16/lbt/lib/modules/exec"
 	"github.com/lspaccatrosi16/lbt/lib/modules/gobuild"
 	"github.com/lspaccatrosi16/lbt/lib/modules/javabuild"
 	"github.com/lspaccatrosi16/lbt/lib/modules/odinbuild"
 	"github.com/lspaccatrosi16/lbt/lib/modules/output"
+	"github.com/lspaccatrosi16/lbt/lib/modules/plugin"
 	"github.com/lspaccatrosi16/lbt/lib/modules/setup"
 	"github.com/lspaccatrosi16/lbt/lib/modules/static"
 	"github.com/lspaccatrosi16/lbt/lib/modules/vbuild"
@@ -34,6 +39,7 @@ var Main = map[string]types.Module{
 	"output":    &output.OutputModule{},
 	"static":    &static.StaticModule{},
 	"compress":  &compress.CompressModule{},
+	"exec":      &exec.ExecModule{},
 }
 
 var Post = map[string]types.Module{
@@ -43,3 +49,38 @@ var Post = map[string]types.Module{
 var PostOrder = []string{
 	"cleanup",
 }
+
+var registerMu sync.Mutex
+
+func Register(m types.Module) error {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	if _, ok := Main[m.Name()]; ok {
+		return fmt.Errorf("module %s is already registered", m.Name())
+	}
+	Main[m.Name()] = m
+	return nil
+}
+
+func ForConfig(config *types.BuildConfig) (map[string]types.Module, error) {
+	paths := []string{}
+	for _, p := range config.ModulePaths {
+		paths = append(paths, config.RelCfgPath(p))
+	}
+
+	plugins, err := plugin.Discover(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	mods := map[string]types.Module{}
+	for name, p := range plugins {
+		mods[name] = p
+	}
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	for name, m := range Main {
+		mods[name] = m
+	}
+	return mods, nil
+}
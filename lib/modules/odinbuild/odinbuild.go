@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
@@ -15,6 +15,7 @@ import (
 type OdinbuildModule struct {
 	bc     *types.BuildConfig
 	config *ModConfig
+	env    []string
 }
 
 type ModConfig struct {
@@ -31,6 +32,11 @@ func (b *OdinbuildModule) Configure(config *types.BuildConfig) error {
 		return err
 	}
 
+	b.env, err = config.Environ("odinbuild")
+	if err != nil {
+		return fmt.Errorf("module odinbuild: %s", err.Error())
+	}
+
 	if cfg.Optimise == "" {
 		cfg.Optimise = "minimal"
 	}
@@ -42,11 +48,12 @@ func (b *OdinbuildModule) Configure(config *types.BuildConfig) error {
 func (b *OdinbuildModule) RunModule(modLogger *log.Logger, target types.Target) bool {
 	ml := modLogger.ChildLogger("odinbuild")
 
-	outPath := filepath.Join(target.TempDir(), "odinbuild", target.ExeName(b.bc.Name, true))
+	name := b.bc.FileName(target, target.ExeName(b.bc.Name, false), types.Executable)
+	outPath := filepath.Join(b.bc.TempDir(target), "odinbuild", name)
 
 	// var err error
 	var stdout, stderr bytes.Buffer
-	cmd := exec.Command("mkdir", "-p", filepath.Dir(outPath))
+	cmd := b.bc.Command("mkdir", "-p", filepath.Dir(outPath))
 
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -63,14 +70,16 @@ func (b *OdinbuildModule) RunModule(modLogger *log.Logger, target types.Target)
 	args := []string{"build", b.bc.RelCfgPath(b.config.Src)}
 	args = append(args, fmt.Sprintf("-out:%s", outPath))
 	args = append(args, fmt.Sprintf("-o:%s", b.config.Optimise))
-	args = append(args, fmt.Sprintf("-target:%s", target.String()))
+	odinOS, odinArch, _ := target.Names(types.ToolchainOdin)
+	args = append(args, fmt.Sprintf("-target:%s_%s", odinOS, odinArch))
 	args = append(args, b.config.Flags...)
 
 	if b.config.Debug {
 		args = append(args, "-debug")
 	}
 
-	cmd = exec.Command("odin", args...)
+	cmd = b.bc.Command("odin", args...)
+	cmd.Env = b.env
 	ml.Logf(log.Info, "command: odin %s\n", strings.Join(args, " "))
 
 	cmd.Stdout = &stdout
@@ -91,7 +100,7 @@ func (b *OdinbuildModule) RunModule(modLogger *log.Logger, target types.Target)
 	f.Chmod(0777)
 	f.Close()
 
-	ml.Logf(log.Info, "Built %s", target.ExeName(b.bc.Name, true))
+	ml.Logf(log.Info, "Built %s", name)
 
 	return true
 }
@@ -115,3 +124,15 @@ func (b *OdinbuildModule) TargetAgnostic() bool {
 func (*OdinbuildModule) RunOnCached() bool {
 	return false
 }
+
+func (*OdinbuildModule) ConfigType() reflect.Type {
+	return reflect.TypeFor[ModConfig]()
+}
+
+func (b *OdinbuildModule) ResolvedConfig() any {
+	return b.config
+}
+
+func (*OdinbuildModule) SupportsTarget(t types.Target) bool {
+	return t.Supports(types.ToolchainOdin)
+}
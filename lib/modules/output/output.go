@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
 	"github.com/lspaccatrosi16/lbt/lib/types"
@@ -31,14 +32,6 @@ func (o *OutputModule) Configure(config *types.BuildConfig) error {
 		return err
 	}
 
-	if cfg.Module == "" {
-		return fmt.Errorf("output module requires module field")
-	}
-
-	if cfg.OutDir == "" {
-		return fmt.Errorf("output module requires outDir field")
-	}
-
 	o.config = cfg
 	return nil
 }
@@ -67,25 +60,25 @@ func (o *OutputModule) RunModule(modLogger *log.Logger, target types.Target) boo
 		}
 	}
 
-	objDir := filepath.Join(target.TempDir(), o.config.Module)
+	objDir := filepath.Join(o.bc.TempDir(target), o.config.Module)
 
 	dE, err := os.ReadDir(objDir)
 	if err != nil {
-				ml.Logln(log.Error, err.Error())
-				return false
+		ml.Logln(log.Error, err.Error())
+		return false
 	}
 
 	for _, e := range dE {
 		err = util.Copy(filepath.Join(oPath, e.Name()), filepath.Join(objDir, e.Name()))
 		if err != nil {
-				ml.Logln(log.Error, err.Error())
-				return false
+			ml.Logln(log.Error, err.Error())
+			return false
 		}
 		ml.Logf(log.Info, "Copied %s to %s", e.Name(), o.config.OutDir)
-		o.bc.Produced = append(o.bc.Produced, filepath.Join(oPath, e.Name()))
+		o.bc.AddProduced(filepath.Join(oPath, e.Name()))
 	}
 
-	return true 
+	return true
 }
 
 func (o *OutputModule) Requires() []string {
@@ -101,5 +94,13 @@ func (o *OutputModule) TargetAgnostic() bool {
 }
 
 func (*OutputModule) RunOnCached() bool {
-	return true 
+	return true
+}
+
+func (*OutputModule) ConfigType() reflect.Type {
+	return reflect.TypeFor[ModuleConfig]()
+}
+
+func (o *OutputModule) ResolvedConfig() any {
+	return o.config
 }
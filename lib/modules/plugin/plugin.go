@@ -0,0 +1,234 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lspaccatrosi16/lbt/lib/log"
+	"github.com/lspaccatrosi16/lbt/lib/types"
+)
+
+const Prefix = "lbt-module-"
+
+type PluginModule struct {
+	name     string
+	path     string
+	bc       *types.BuildConfig
+	config   map[string]interface{}
+	requires []string
+	agnostic bool
+}
+
+func New(name, path string) *PluginModule {
+	return &PluginModule{name: name, path: path}
+}
+
+func Discover(paths []string) (map[string]*PluginModule, error) {
+	found := map[string]*PluginModule{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		scanDir(dir, found)
+	}
+
+	for _, p := range paths {
+		s, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("module path %s: %s", p, err.Error())
+		}
+		if s.IsDir() {
+			scanDir(p, found)
+			continue
+		}
+		name, ok := strings.CutPrefix(filepath.Base(p), Prefix)
+		if !ok {
+			return nil, fmt.Errorf("module path %s: plugin executables must be named %s<name>", p, Prefix)
+		}
+		found[strings.TrimSuffix(name, ".exe")] = New(strings.TrimSuffix(name, ".exe"), p)
+	}
+	return found, nil
+}
+
+func scanDir(dir string, found map[string]*PluginModule) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name, ok := strings.CutPrefix(e.Name(), Prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		name = strings.TrimSuffix(name, ".exe")
+		if _, seen := found[name]; !seen {
+			found[name] = New(name, filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+type Request struct {
+	Method    string                 `json:"method"`
+	Name      string                 `json:"name"`
+	Build     string                 `json:"build"`
+	Root      string                 `json:"root"`
+	Target    types.Target           `json:"target"`
+	TempDir   string                 `json:"tempDir"`
+	ModuleDir string                 `json:"moduleDir"`
+	Config    map[string]interface{} `json:"config"`
+}
+
+type LogLine struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+type Result struct {
+	Name           string   `json:"name,omitempty"`
+	Requires       []string `json:"requires,omitempty"`
+	TargetAgnostic bool     `json:"targetAgnostic,omitempty"`
+	OK             bool     `json:"ok"`
+}
+
+type Message struct {
+	Log    *LogLine `json:"log,omitempty"`
+	Result *Result  `json:"result,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+func (p *PluginModule) Configure(config *types.BuildConfig) error {
+	p.bc = config
+	cfg, err := types.GetModConfig[map[string]interface{}](config, p.name)
+	if err != nil {
+		return err
+	}
+	p.config = *cfg
+
+	target := types.NoTarget
+	if config.Context != nil {
+		target = config.Context.Target
+	}
+	v, err := config.Memo(fmt.Sprintf("plugin/%s/%s", p.name, target.String()), func() (interface{}, error) {
+		return p.call("configure", log.Default.ChildLogger(p.name), target)
+	})
+	if err != nil {
+		return fmt.Errorf("module %s: %s", p.name, err.Error())
+	}
+	res := v.(*Result)
+	if res.Name != "" && res.Name != p.name {
+		return fmt.Errorf("module %s: plugin %s reports its name as %s", p.name, p.path, res.Name)
+	}
+	p.requires = res.Requires
+	p.agnostic = res.TargetAgnostic
+	return nil
+}
+
+func (p *PluginModule) RunModule(modLogger *log.Logger, target types.Target) bool {
+	ml := modLogger.ChildLogger(p.name)
+	res, err := p.call("run", ml, target)
+	if err != nil {
+		ml.Logln(log.Error, err.Error())
+		return false
+	}
+	return res.OK
+}
+
+func (p *PluginModule) call(method string, ml *log.Logger, target types.Target) (*Result, error) {
+	req := Request{
+		Method:  method,
+		Name:    p.name,
+		Build:   p.bc.Name,
+		Root:    p.bc.RelCfgPath(),
+		Target:  target,
+		TempDir: p.bc.TempDir(target),
+		Config:  p.config,
+	}
+	if p.bc.Context != nil {
+		req.ModuleDir = filepath.Join(p.bc.TempDir(target), p.name)
+	}
+
+	by, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := p.bc.Environ(p.name)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := p.bc.Command(p.path)
+	cmd.Dir = p.bc.RelCfgPath()
+	cmd.Env = append(env, p.bc.ModuleVars(p.name)...)
+	cmd.Stdin = bytes.NewReader(append(by, '\n'))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	var res *Result
+	var perr string
+	sc := bufio.NewScanner(stdout)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		var msg Message
+		if json.Unmarshal([]byte(line), &msg) != nil {
+			ml.Logln(log.Info, line)
+			continue
+		}
+		switch {
+		case msg.Log != nil:
+			lvl, err := log.ParseLogLevel(msg.Log.Level)
+			if err != nil {
+				lvl = log.Info
+			}
+			ml.Logln(lvl, msg.Log.Message)
+		case msg.Error != "":
+			perr = msg.Error
+		case msg.Result != nil:
+			res = msg.Result
+		}
+	}
+
+	err = cmd.Wait()
+	if perr != "" {
+		return nil, fmt.Errorf("%s", perr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %s\n%s", p.path, method, err.Error(), stderr.String())
+	}
+	if res == nil {
+		return nil, fmt.Errorf("%s %s: plugin did not send a result", p.path, method)
+	}
+	return res, nil
+}
+
+func (p *PluginModule) Name() string {
+	return p.name
+}
+
+func (p *PluginModule) Requires() []string {
+	return p.requires
+}
+
+func (p *PluginModule) OnFail() error {
+	return nil
+}
+
+func (p *PluginModule) TargetAgnostic() bool {
+	return p.agnostic
+}
+
+func (*PluginModule) RunOnCached() bool {
+	return false
+}
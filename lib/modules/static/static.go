@@ -1,9 +1,9 @@
 package static
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
 	"github.com/lspaccatrosi16/lbt/lib/types"
@@ -38,24 +38,6 @@ func (s *StaticModule) Configure(config *types.BuildConfig) error {
 		return err
 	}
 
-	for i, str := range cfg.Structures {
-		if str.Name == "" {
-			return fmt.Errorf("static module requires name field in structure %d", i+1)
-		}
-
-		if str.Path == "" {
-			return fmt.Errorf("static module requires path field in structure %d", i+1)
-		}
-
-		for j, exe := range str.Executables {
-			if exe.Command == "" {
-				return fmt.Errorf("static module requires command field in structure %d executable %d", i+1, j+1)
-			}
-			if exe.Path == "" {
-				return fmt.Errorf("static module requires path field in structure %d executable %d", i+1, j+1)
-			}
-		}
-	}
 	s.config = cfg
 	return nil
 }
@@ -63,7 +45,7 @@ func (s *StaticModule) Configure(config *types.BuildConfig) error {
 func (s *StaticModule) RunModule(modLogger *log.Logger, target types.Target) bool {
 	ml := modLogger.ChildLogger("static")
 
-	based := target.TempDir()
+	based := s.bc.TempDir(target)
 	exeDir := filepath.Join(based, s.config.Module)
 	oPath := filepath.Join(based, "static")
 
@@ -130,3 +112,11 @@ func (s *StaticModule) TargetAgnostic() bool {
 func (*StaticModule) RunOnCached() bool {
 	return false
 }
+
+func (*StaticModule) ConfigType() reflect.Type {
+	return reflect.TypeFor[ModConfig]()
+}
+
+func (s *StaticModule) ResolvedConfig() any {
+	return s.config
+}
@@ -2,9 +2,10 @@ package vbuild
 
 import (
 	"bytes"
+	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
@@ -14,6 +15,7 @@ import (
 type VbuildModule struct {
 	bc     *types.BuildConfig
 	config *ModConfig
+	env    []string
 }
 
 type ModConfig struct {
@@ -30,6 +32,11 @@ func (b *VbuildModule) Configure(config *types.BuildConfig) error {
 		return err
 	}
 
+	b.env, err = config.Environ("vbuild")
+	if err != nil {
+		return fmt.Errorf("module vbuild: %s", err.Error())
+	}
+
 	if cfg.Backend == "" {
 		cfg.Backend = "c"
 	}
@@ -41,11 +48,12 @@ func (b *VbuildModule) Configure(config *types.BuildConfig) error {
 func (b *VbuildModule) RunModule(modLogger *log.Logger, target types.Target) bool {
 	ml := modLogger.ChildLogger("vbuild")
 
-	outPath := filepath.Join(target.TempDir(), "vbuild", target.ExeName(b.bc.Name, true))
+	name := b.bc.FileName(target, target.ExeName(b.bc.Name, false), types.Executable)
+	outPath := filepath.Join(b.bc.TempDir(target), "vbuild", name)
 
 	// var err error
 	var stdout, stderr bytes.Buffer
-	cmd := exec.Command("mkdir", "-p", filepath.Dir(outPath))
+	cmd := b.bc.Command("mkdir", "-p", filepath.Dir(outPath))
 
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -61,8 +69,9 @@ func (b *VbuildModule) RunModule(modLogger *log.Logger, target types.Target) boo
 
 	args := []string{b.bc.RelCfgPath(b.config.Src)}
 	args = append(args, "-o", outPath)
-	args = append(args, "-os", string(target.OS))
-	args = append(args, "-arch", string(target.Arch))
+	vOS, vArch, _ := target.Names(types.ToolchainV)
+	args = append(args, "-os", vOS)
+	args = append(args, "-arch", vArch)
 	args = append(args, "-backend", b.config.Backend)
 
 	if b.config.Debug {
@@ -71,7 +80,8 @@ func (b *VbuildModule) RunModule(modLogger *log.Logger, target types.Target) boo
 
 	args = append(args, b.config.Flags...)
 
-	cmd = exec.Command("v", args...)
+	cmd = b.bc.Command("v", args...)
+	cmd.Env = b.env
 	ml.Logf(log.Info, "command: v %s\n", strings.Join(args, " "))
 
 	cmd.Stdout = &stdout
@@ -94,7 +104,7 @@ func (b *VbuildModule) RunModule(modLogger *log.Logger, target types.Target) boo
 	f.Chmod(0777)
 	f.Close()
 
-	ml.Logf(log.Info, "Built %s", target.ExeName(b.bc.Name, true))
+	ml.Logf(log.Info, "Built %s", name)
 
 	return true
 }
@@ -118,3 +128,15 @@ func (b *VbuildModule) TargetAgnostic() bool {
 func (*VbuildModule) RunOnCached() bool {
 	return false
 }
+
+func (*VbuildModule) ConfigType() reflect.Type {
+	return reflect.TypeFor[ModConfig]()
+}
+
+func (b *VbuildModule) ResolvedConfig() any {
+	return b.config
+}
+
+func (*VbuildModule) SupportsTarget(t types.Target) bool {
+	return t.Supports(types.ToolchainV)
+}
@@ -1,15 +1,14 @@
 package version
 
 import (
-	"bytes"
 	"fmt"
-	"io"
 	"math/rand"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
+	"github.com/lspaccatrosi16/lbt/lib/semver"
 	"github.com/lspaccatrosi16/lbt/lib/types"
 )
 
@@ -76,60 +75,49 @@ func (v *VersionModule) RunModule(modLogger *log.Logger, _ types.Target) bool {
 	ml := modLogger.ChildLogger("version")
 	var newVersion string
 
-	f, err := os.Create(v.bc.RelCfgPath(v.bc.Version.Path))
-	if err == nil {
-		by, err := io.ReadAll(f)
-		if err != nil {
-			ml.Logln(log.Error, err.Error())
-			return false
-		}
-		v.prev = string(by)
-		f.Close()
+	by, err := os.ReadFile(v.bc.RelCfgPath(v.bc.Version.Path))
+	if err != nil && !os.IsNotExist(err) {
+		ml.Logln(log.Error, err.Error())
+		return false
 	}
+	v.prev = string(by)
 
 	switch v.config.VerType {
 	case VersionBuildStr:
 		newVersion = strconv.FormatInt(rand.Int63(), 36)
 	case VersionBuildInt:
-		f, err := os.Open(v.bc.Version.Path)
-		if err != nil {
-			ml.Logln(log.Error, err.Error())
-			return false
-		}
-		buf := bytes.NewBuffer(nil)
-		io.Copy(buf, f)
-		f.Close()
-		curVer, err := strconv.Atoi(strings.Trim(buf.String(), " \r\n\t"))
-		if err != nil {
-			ml.Logln(log.Error, err.Error())
-			return false
+		curVer := 0
+		if trimmed := strings.Trim(v.prev, " \r\n\t"); trimmed != "" {
+			curVer, err = strconv.Atoi(trimmed)
+			if err != nil {
+				ml.Logln(log.Error, err.Error())
+				return false
+			}
 		}
 		newVersion = strconv.Itoa(curVer + 1)
 	case VersionSemVer:
-		f, err := os.Open(v.bc.Version.Path)
+		curVer, err := semver.Parse(v.prev)
 		if err != nil {
 			ml.Logln(log.Error, err.Error())
 			return false
 		}
-		buf := bytes.NewBuffer(nil)
-		io.Copy(buf, f)
-		f.Close()
-		curVer := buf.String()
-		verParts := []int{0, 0, 0, 0}
-		fmt.Sscanf(curVer, "%d.%d.%d.%d", &verParts[0], &verParts[1], &verParts[2], &verParts[3])
-		verParts[3]++
-		newVersion = fmt.Sprintf("%d.%d.%d.%d", verParts[0], verParts[1], verParts[2], verParts[3])
+		curVer.Counter++
+		newVersion = curVer.String()
 	}
 
 	ml.Logf(log.Info, "new version: %s", newVersion)
 
-	f, err = os.Create(v.bc.RelCfgPath(v.bc.Version.Path))
+	f, err := os.Create(v.bc.RelCfgPath(v.bc.Version.Path))
 	if err != nil {
 		ml.Logln(log.Error, err.Error())
 		return false
 	}
 	f.WriteString(newVersion)
 	f.Close()
+
+	if v.bc.Context != nil {
+		v.bc.Context.Version = newVersion
+	}
 	return true
 }
 
@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -51,6 +52,9 @@ type Job struct {
 	target      types.Target
 	canParallel bool
 	ml          *log.Logger
+	failJobs    []*Job
+	skipped     bool
+	condition   func() (bool, error)
 }
 
 func (j *Job) NewChild(name string) *Job {
@@ -60,8 +64,30 @@ func (j *Job) NewChild(name string) *Job {
 	return nj
 }
 
+func (j *Job) NewFailureChild(name string) *Job {
+	nj := NewJob(name)
+	nj.level = j.level + 1
+	j.failJobs = append(j.failJobs, nj)
+	return nj
+}
+
 func (j *Job) Run() bool {
 	var res bool
+	if j.condition != nil {
+		ok, err := j.condition()
+		if err != nil {
+			if j.ml != nil {
+				j.ml.ChildLogger(j.name).Logln(log.Error, err.Error())
+			}
+			res = false
+			goto end
+		}
+		if !ok {
+			j.skipped = true
+			return true
+		}
+	}
+
 	if len(j.jobs) > 0 || j.runner == nil {
 		res = true
 		if j.canParallel {
@@ -87,11 +113,21 @@ func (j *Job) Run() bool {
 					break
 				}
 			}
+			for _, fj := range j.failJobs {
+				if res {
+					fj.skipped = true
+				} else {
+					fj.Run()
+				}
+			}
 		}
 	} else {
 		if j.configure != nil {
 			err := j.configure()
 			if err != nil {
+				if j.ml != nil {
+					j.ml.ChildLogger(j.name).Logln(log.Error, err.Error())
+				}
 				res = false
 				goto end
 			}
@@ -120,13 +156,14 @@ func (j *Job) line() []*jobstat {
 		status:  j.completed,
 		failed:  j.failed,
 		spinner: true,
+		skipped: j.skipped,
 	}
 
 	stats := []*jobstat{&mj}
 
-	for _, js := range j.jobs {
+	for _, js := range slices.Concat(j.jobs, j.failJobs) {
 		for _, lin := range js.line() {
-			if j.failed && !lin.status && !lin.failed {
+			if (j.failed || j.skipped) && !lin.status && !lin.failed {
 				lin.skipped = true
 			}
 			stats = append(stats, lin)
@@ -151,6 +188,11 @@ func (j *Job) WithConfigure(configure func() error) *Job {
 	return j
 }
 
+func (j *Job) WithCondition(condition func() (bool, error)) *Job {
+	j.condition = condition
+	return j
+}
+
 func (j *Job) WithParallel() *Job {
 	j.canParallel = true
 	return j
@@ -176,6 +218,27 @@ type Progress struct {
 func (p *Progress) Render(name string) bool {
 	p.wg.Add(1)
 	go p.render(name)
+	res := p.runJobs()
+	p.wg.Wait()
+
+	if !res {
+		p.genFrame(name, os.Stdout)
+	}
+
+	return res
+}
+
+// Run runs the jobs without the interactive display. If any job fails, the
+// final state of every job is written to w.
+func (p *Progress) Run(name string, w io.Writer) bool {
+	res := p.runJobs()
+	if !res {
+		p.genFrame(name, w)
+	}
+	return res
+}
+
+func (p *Progress) runJobs() bool {
 	res := true
 	for _, jg := range p.jobs {
 		r := jg.Run()
@@ -183,12 +246,6 @@ func (p *Progress) Render(name string) bool {
 			res = false
 		}
 	}
-	p.wg.Wait()
-
-	if !res {
-		p.genFrame(name, os.Stdout)
-	}
-
 	return res
 }
 
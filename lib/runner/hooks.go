@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/lspaccatrosi16/lbt/lib/log"
+	"github.com/lspaccatrosi16/lbt/lib/progress"
+	"github.com/lspaccatrosi16/lbt/lib/types"
+	"github.com/lspaccatrosi16/lbt/lib/util"
+)
+
+const hookNameLen = 32
+
+func addModuleJob(tg *progress.Job, ml *log.Logger, config *types.BuildConfig, modName string, inst types.Module, targ types.Target) {
+	hooks := config.ForTarget(targ).ModuleHooks(modName)
+	enabled := func() (bool, error) {
+		return config.ForTarget(targ).ModuleEnabled(modName)
+	}
+	if hooks.Empty() {
+		tg.NewChild(modName).WithCondition(enabled).WithFunc(guard(config, inst.RunModule)).WithConfigure(configureTarget(inst, config, targ)).WithTarget(targ).WithLog(ml)
+		return
+	}
+
+	mj := tg.NewChild(modName).WithCondition(enabled).WithLog(ml)
+	for _, h := range hooks.Before {
+		mj.NewChild(hookName("before", h)).WithFunc(guard(config, runHook(config, modName, h))).WithTarget(targ).WithLog(ml)
+	}
+	mj.NewChild("run").WithFunc(guard(config, inst.RunModule)).WithConfigure(configureTarget(inst, config, targ)).WithTarget(targ).WithLog(ml)
+	for _, h := range hooks.After {
+		mj.NewChild(hookName("after", h)).WithFunc(guard(config, runHook(config, modName, h))).WithTarget(targ).WithLog(ml)
+	}
+	for _, h := range hooks.OnFailure {
+		mj.NewFailureChild(hookName("onFailure", h)).WithFunc(guard(config, runHook(config, modName, h))).WithTarget(targ).WithLog(ml)
+	}
+}
+
+func guard(config *types.BuildConfig, f func(*log.Logger, types.Target) bool) func(*log.Logger, types.Target) bool {
+	return func(ml *log.Logger, target types.Target) bool {
+		if err := config.Context.Err(); err != nil {
+			ml.Logln(log.Error, err.Error())
+			return false
+		}
+		return f(ml, target)
+	}
+}
+
+func hookName(kind, cmd string) string {
+	if len(cmd) > hookNameLen {
+		cmd = cmd[:hookNameLen-3] + "..."
+	}
+	return fmt.Sprintf("%s: %s", kind, cmd)
+}
+
+func runHook(config *types.BuildConfig, modName, hook string) func(*log.Logger, types.Target) bool {
+	return func(modLogger *log.Logger, target types.Target) bool {
+		ml := modLogger.ChildLogger(modName).ChildLogger("hook")
+		tc := config.ForTarget(target)
+
+		cmdStr, err := tc.Context.Expand(hook)
+		if err != nil {
+			ml.Logln(log.Error, err.Error())
+			return false
+		}
+
+		env, err := tc.Environ(modName)
+		if err != nil {
+			ml.Logln(log.Error, err.Error())
+			return false
+		}
+
+		cmd := tc.Command("sh", "-c", cmdStr)
+		cmd.Env = append(env, tc.ModuleVars(modName)...)
+		ml.Logf(log.Info, "command: %s", cmdStr)
+
+		var stdout, stderr bytes.Buffer
+		return util.RunCmd(cmd, stdout, stderr, ml, tc.RelCfgPath())
+	}
+}
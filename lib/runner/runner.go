@@ -3,92 +3,144 @@ package runner
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"slices"
 	"strings"
 
-	"github.com/lspaccatrosi16/go-cli-tools/args"
 	"github.com/lspaccatrosi16/lbt/lib/log"
 	"github.com/lspaccatrosi16/lbt/lib/modules"
 	"github.com/lspaccatrosi16/lbt/lib/progress"
 	"github.com/lspaccatrosi16/lbt/lib/types"
+	"github.com/lspaccatrosi16/lbt/lib/validate"
 )
 
-func RunModules(config *types.BuildConfig, mainMods map[string]types.Module, cached bool) error {
+type Build struct {
+	Config *types.BuildConfig
+	Job    *progress.Job
+	logs   []targetLog
+}
+
+type targetLog struct {
+	name string
+	buf  *bytes.Buffer
+}
+
+func RunModules(config *types.BuildConfig, mainMods map[string]types.Module, cached bool, targets []string, keepTemp bool, out io.Writer) error {
+	ml := Logger(out)
+
+	b, err := NewBuild(progress.NewJob("lbt"), ml, config, mainMods, cached, targets)
+	if err != nil {
+		return err
+	}
+
+	cleanupJob := NewCleanup(ml, config, cached, keepTemp)
+	return Render(fmt.Sprintf("build %s", config.Name), out, b.Job, cleanupJob, b)
+}
+
+// Logger returns the logger for a build writing to out, or to the default
+// log output if out is nil.
+func Logger(out io.Writer) *log.Logger {
 	ml := log.Default.ChildLogger("build")
+	if out != nil {
+		ml.OverrideWriter(out)
+	}
+	return ml
+}
+
+func NewBuild(job *progress.Job, ml *log.Logger, config *types.BuildConfig, mainMods map[string]types.Module, cached bool, targets []string) (*Build, error) {
+	b := &Build{Config: config, Job: job}
 
-	job := progress.NewJob("lbt")
 	preHooksJob := job.NewChild("pre-build")
 	for _, modName := range modules.PreOrder {
-		mod := modules.Pre[modName]
+		mod := types.NewInstance(modules.Pre[modName])
 		if !cached || mod.RunOnCached() {
-			preHooksJob.NewChild(mod.Name()).WithFunc(mod.RunModule).WithConfigure(WrapConfig(mod.Configure, config)).WithLog(ml)
+			preHooksJob.NewChild(mod.Name()).WithFunc(guard(config, mod.RunModule)).WithConfigure(WrapConfig(mod.Configure, config)).WithLog(ml)
 		}
 	}
-	tls := map[types.Target]*bytes.Buffer{}
 
 	if len(config.Modules) > 0 {
-		targFilter, err := args.GetFlagValue[string]("targFilter")
-		if err != nil {
-			return err
+		selected := []types.Target{}
+		for _, targ := range config.Targets {
+			if len(targets) == 0 || slices.Contains(targets, targ.String()) {
+				selected = append(selected, targ)
+			}
 		}
 
-		filters := strings.Split(targFilter, ",")
-		for i := range filters {
-			filters[i] = strings.TrimSpace(filters[i])
+		err := ValidateModules(config, mainMods, selected)
+		if err != nil {
+			return nil, err
 		}
-		mainJob := job.NewChild("build").WithParallel()
 
-		order := []string{}
-		for _, m := range config.Modules {
-			order, err = orderModules(config, m.Name, order, mainMods)
-			if err != nil {
-				return err
-			}
+		order, err := OrderModules(config, mainMods, selected)
+		if err != nil {
+			return nil, err
 		}
 
-		for _, targ := range config.Targets {
-			if targFilter == "" || slices.Contains(filters, targ.String()) {
-				buf := bytes.NewBuffer(nil)
-				tl := ml.ChildLogger(targ.String()).OverrideWriter(buf)
-				tls[targ] = buf
-				tg := mainJob.NewChild(targ.String()).WithLog(tl)
-				for _, modName := range order {
-					mod := mainMods[modName]
-					if !cached || mod.RunOnCached() {
-						tg.NewChild(modName).WithFunc(mod.RunModule).WithTarget(targ).WithLog(tl)
-					}
+		mainJob := job.NewChild("build").WithParallel()
+
+		for _, targ := range selected {
+			buf := bytes.NewBuffer(nil)
+			tl := ml.ChildLogger(targ.String()).OverrideWriter(buf)
+			b.logs = append(b.logs, targetLog{name: targ.String(), buf: buf})
+			tg := mainJob.NewChild(targ.String()).WithLog(tl)
+			tc := config.ForTarget(targ)
+			for _, modName := range order {
+				mod := mainMods[modName]
+				if tc.ModuleTargeted(modName) && (!cached || mod.RunOnCached()) {
+					addModuleJob(tg, tl, config, modName, types.NewInstance(mod), targ)
 				}
 			}
 		}
 	}
 
-	cleanupJob := progress.NewJob("post-build")
-	nc, err := args.GetFlagValue[bool]("nc")
-	if err != nil {
-		return err
-	}
+	return b, nil
+}
 
-	if nc {
-		fmt.Println(types.NoTarget.TempDir())
+func NewCleanup(ml *log.Logger, config *types.BuildConfig, cached bool, keepTemp bool) *progress.Job {
+	cleanupJob := progress.NewJob("post-build")
+	if keepTemp {
+		return cleanupJob
 	}
 
 	for _, modName := range modules.PostOrder {
-		mod := modules.Post[modName]
-		if (!cached || mod.RunOnCached()) && !nc {
+		mod := types.NewInstance(modules.Post[modName])
+		if !cached || mod.RunOnCached() {
 			cleanupJob.NewChild(mod.Name()).WithFunc(mod.RunModule).WithConfigure(WrapConfig(mod.Configure, config)).WithLog(ml)
 		}
 	}
 
+	return cleanupJob
+}
+
+// Render runs the jobs of one or more builds and prints their logs. With a
+// nil out, progress is drawn interactively on stdout. Otherwise the jobs run
+// without the display and everything is written to out, so that several
+// builds can run at once.
+func Render(title string, out io.Writer, job *progress.Job, cleanupJob *progress.Job, builds ...*Build) error {
 	progress := progress.
 		NewProgress(job, cleanupJob)
-	res := progress.Render(fmt.Sprintf("build %s", config.Name))
 
-	for t, b := range tls {
-		if b.String() == "" {
-			continue
+	var res bool
+	if out == nil {
+		out = os.Stdout
+		res = progress.Render(title)
+	} else {
+		res = progress.Run(title, out)
+	}
+
+	for _, b := range builds {
+		for _, tl := range b.logs {
+			if tl.buf.String() == "" {
+				continue
+			}
+			if len(builds) > 1 {
+				fmt.Fprintf(out, "[%s %s]\n", b.Config.Name, tl.name)
+			} else {
+				fmt.Fprintf(out, "[%s]\n", tl.name)
+			}
+			fmt.Fprintln(out, tl.buf.String())
 		}
-		fmt.Printf("[%s]\n", t.String())
-		fmt.Println(b.String())
 	}
 
 	if !res {
@@ -98,42 +150,108 @@ func RunModules(config *types.BuildConfig, mainMods map[string]types.Module, cac
 	return nil
 }
 
-var modsSeen = map[string]bool{}
+func ValidateModules(config *types.BuildConfig, mainMods map[string]types.Module, targets []types.Target) error {
+	known := []string{}
+	for name := range mainMods {
+		known = append(known, name)
+	}
+
+	errs := []string{}
+	for _, m := range config.Modules {
+		proto, ok := mainMods[m.Name]
+		if !ok {
+			if s := validate.Suggest(m.Name, known); s != "" {
+				errs = append(errs, fmt.Sprintf("unknown module %s, did you mean %s?", m.Name, s))
+			} else {
+				errs = append(errs, fmt.Sprintf("unknown module %s", m.Name))
+			}
+			continue
+		}
+
+		for _, tc := range targetConfigs(config, proto, targets) {
+			err := types.NewInstance(proto).Configure(tc)
+			if err != nil && !slices.Contains(errs, err.Error()) {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func OrderModules(config *types.BuildConfig, mainMods map[string]types.Module, targets []types.Target) ([]string, error) {
+	order := []string{}
+	for _, m := range config.Modules {
+		var err error
+		order, err = orderModules(config, targets, m.Name, order, mainMods, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
 
-func orderModules(config *types.BuildConfig, modName string, order []string, mainMods map[string]types.Module) ([]string, error) {
-	if ok := modsSeen[modName]; ok {
+func orderModules(config *types.BuildConfig, targets []types.Target, modName string, order []string, mainMods map[string]types.Module, path []string) ([]string, error) {
+	if slices.Contains(path, modName) {
 		return nil, fmt.Errorf("requirement cycle detected around module %s", modName)
 	}
 
-	modsSeen[modName] = true
+	if slices.Contains(order, modName) {
+		return order, nil
+	}
 
-	mod, ok := mainMods[modName]
+	proto, ok := mainMods[modName]
 	if !ok {
 		return nil, fmt.Errorf("module %s was specified, but could not be found", modName)
 	}
 
-	err := mod.Configure(config)
-	if err != nil {
-		return nil, err
+	requires := []string{}
+	for _, tc := range targetConfigs(config, proto, targets) {
+		mod := types.NewInstance(proto)
+		err := mod.Configure(tc)
+		if err != nil {
+			return nil, err
+		}
+		for _, req := range mod.Requires() {
+			if !slices.Contains(requires, req) {
+				requires = append(requires, req)
+			}
+		}
 	}
 
-	requirements := mod.Requires()
-	if len(requirements) == 0 {
-		return append([]string{modName}, order...), nil
-	} else {
-		for _, req := range requirements {
-			inOrder := slices.Contains(order, req)
-			if !inOrder {
-				withReq, err := orderModules(config, req, order, mainMods)
-				if err != nil {
-					return nil, err
-				}
-				order = withReq
-			}
+	for _, req := range requires {
+		var err error
+		order, err = orderModules(config, targets, req, order, mainMods, append(path, modName))
+		if err != nil {
+			return nil, err
 		}
-		order = append(order, modName)
 	}
-	return order, nil
+
+	return append(order, modName), nil
+}
+
+func targetConfigs(config *types.BuildConfig, mod types.Module, targets []types.Target) []*types.BuildConfig {
+	configs := []*types.BuildConfig{}
+	for _, t := range targets {
+		tc := config.ForTarget(t)
+		if tc.ModuleTargeted(mod.Name()) && types.CheckTarget(mod, t) == nil {
+			configs = append(configs, tc)
+		}
+	}
+	return configs
+}
+
+func configureTarget(mod types.Module, config *types.BuildConfig, target types.Target) func() error {
+	return func() error {
+		err := types.CheckTarget(mod, target)
+		if err != nil {
+			return err
+		}
+		return mod.Configure(config.ForTarget(target))
+	}
 }
 
 func WrapConfig(configurer func(*types.BuildConfig) error, config *types.BuildConfig) func() error {
@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/lspaccatrosi16/lbt/lib/types"
+	"github.com/lspaccatrosi16/lbt/lib/validate"
+)
+
+type Schema map[string]interface{}
+
+var osType = reflect.TypeFor[types.OS]()
+var archType = reflect.TypeFor[types.Arch]()
+var stringListType = reflect.TypeFor[types.StringList]()
+var targetListType = reflect.TypeFor[types.TargetList]()
+var moduleConfigType = reflect.TypeFor[types.ModuleConfig]()
+var profileType = reflect.TypeFor[types.Profile]()
+
+func Generate(mods map[string]types.Module) Schema {
+	names := []string{}
+	for name := range mods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	g := &generator{names: names}
+	full := []interface{}{}
+	partial := []interface{}{}
+	for _, name := range names {
+		cm, ok := mods[name].(types.ConfigurableModule)
+		if !ok {
+			continue
+		}
+		overrides := g.typeSchema(cm.ConfigType(), true)
+		full = append(full, g.moduleCase(name, g.typeSchema(cm.ConfigType(), false), overrides))
+		partial = append(partial, g.moduleCase(name, g.typeSchema(cm.ConfigType(), true), overrides))
+	}
+
+	g.module = g.moduleSchema(full)
+	g.partialModule = g.moduleSchema(partial)
+
+	s := g.typeSchema(reflect.TypeFor[types.BuildConfig](), false)
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["title"] = "lbt config"
+	s["definitions"] = Schema{
+		"module":        g.module,
+		"partialModule": g.partialModule,
+	}
+	return s
+}
+
+type generator struct {
+	names         []string
+	module        Schema
+	partialModule Schema
+}
+
+func (g *generator) moduleCase(name string, config Schema, overrides Schema) Schema {
+	return Schema{
+		"if": Schema{
+			"properties": Schema{"name": Schema{"const": name}},
+		},
+		"then": Schema{
+			"properties": Schema{
+				"config":    config,
+				"overrides": Schema{"type": "object", "additionalProperties": overrides},
+			},
+		},
+	}
+}
+
+func (g *generator) moduleSchema(cases []interface{}) Schema {
+	names := []interface{}{}
+	for _, n := range g.names {
+		names = append(names, n)
+	}
+	return Schema{
+		"type": "object",
+		"properties": Schema{
+			"name":      Schema{"anyOf": []Schema{{"enum": names}, {"type": "string"}}},
+			"config":    Schema{"type": "object"},
+			"targets":   g.typeSchema(stringListType, false),
+			"when":      Schema{"type": "string"},
+			"overrides": Schema{"type": "object"},
+			"env":       Schema{"type": "object", "additionalProperties": Schema{"type": "string"}},
+			"hooks":     g.typeSchema(reflect.TypeFor[types.Hooks](), false),
+		},
+		"required":             []string{"name"},
+		"additionalProperties": false,
+		"allOf":                cases,
+	}
+}
+
+func (g *generator) typeSchema(t reflect.Type, partial bool) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case osType:
+		names := []string{}
+		for _, o := range types.OSList() {
+			names = append(names, string(o.Name))
+		}
+		return Schema{"type": "string", "enum": names}
+	case archType:
+		names := []string{}
+		for _, a := range types.ArchList() {
+			names = append(names, string(a.Name))
+			names = append(names, a.Aliases...)
+		}
+		return Schema{"type": "string", "enum": names}
+	case targetListType:
+		return Schema{"oneOf": []interface{}{
+			Schema{"type": "array", "items": g.typeSchema(reflect.TypeFor[types.Target](), partial)},
+			Schema{
+				"type": "object",
+				"properties": Schema{
+					"os":      g.typeSchema(stringListType, partial),
+					"arch":    g.typeSchema(stringListType, partial),
+					"exclude": g.typeSchema(stringListType, partial),
+				},
+				"required":             []string{"os", "arch"},
+				"additionalProperties": false,
+			},
+		}}
+	case stringListType:
+		return Schema{"oneOf": []interface{}{
+			Schema{"type": "string"},
+			Schema{"type": "array", "items": Schema{"type": "string"}},
+		}}
+	case profileType:
+		partial = true
+	case moduleConfigType:
+		if partial {
+			return Schema{"$ref": "#/definitions/partialModule"}
+		}
+		return Schema{"$ref": "#/definitions/module"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		props := Schema{}
+		required := []string{}
+		for _, f := range validate.Fields(t) {
+			fs := g.typeSchema(f.Type(), partial)
+			if opts := f.OneOf(); len(opts) > 0 {
+				fs["enum"] = opts
+			}
+			props[f.Name()] = fs
+			if f.Required() && !partial {
+				required = append(required, f.Name())
+			}
+		}
+		s := Schema{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.typeSchema(t.Elem(), partial)}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.typeSchema(t.Elem(), partial)}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	}
+	return Schema{}
+}
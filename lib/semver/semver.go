@@ -0,0 +1,196 @@
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Version struct {
+	Major   uint64
+	Minor   uint64
+	Patch   uint64
+	Counter uint64
+	Pre     []string
+	Build   []string
+
+	// counterInBuild records that the counter was read from build
+	// metadata, so String writes it back there instead of as a fourth
+	// numeric component.
+	counterInBuild bool
+}
+
+func Parse(s string) (*Version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if s == "" {
+		return &Version{}, nil
+	}
+
+	v := &Version{}
+	core := s
+
+	if i := strings.Index(core, "+"); i >= 0 {
+		v.Build = strings.Split(core[i+1:], ".")
+		core = core[:i]
+	}
+
+	if i := strings.Index(core, "-"); i >= 0 {
+		v.Pre = strings.Split(core[i+1:], ".")
+		core = core[:i]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 && len(parts) != 4 {
+		return nil, fmt.Errorf("invalid version %q: expected major.minor.patch", s)
+	}
+
+	nums := []*uint64{&v.Major, &v.Minor, &v.Patch, &v.Counter}
+	for i, p := range parts {
+		n, err := parseNumeric(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %s", s, err.Error())
+		}
+		*nums[i] = n
+	}
+
+	if v.Counter == 0 && len(v.Build) >= 2 && v.Build[0] == counterIdent && isNumeric(v.Build[1]) {
+		n, err := parseNumeric(v.Build[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %s", s, err.Error())
+		}
+		v.Counter = n
+		v.Build = v.Build[2:]
+		v.counterInBuild = true
+	}
+
+	if err := v.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid version %q: %s", s, err.Error())
+	}
+
+	return v, nil
+}
+
+const counterIdent = "build"
+
+func parseNumeric(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty numeric component")
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("numeric component %q has a leading zero", s)
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+func (v *Version) Validate() error {
+	for _, p := range v.Pre {
+		if err := validIdent(p); err != nil {
+			return fmt.Errorf("pre-release %s", err.Error())
+		}
+		if isNumeric(p) && len(p) > 1 && p[0] == '0' {
+			return fmt.Errorf("pre-release identifier %q has a leading zero", p)
+		}
+	}
+
+	for _, b := range v.Build {
+		if err := validIdent(b); err != nil {
+			return fmt.Errorf("build metadata %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func validIdent(s string) error {
+	if s == "" {
+		return fmt.Errorf("identifier cannot be empty")
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+			return fmt.Errorf("identifier %q contains invalid character %q", s, c)
+		}
+	}
+	return nil
+}
+
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (v *Version) Bump(part string, preid string) error {
+	switch part {
+	case "major":
+		if len(v.Pre) == 0 || v.Minor != 0 || v.Patch != 0 {
+			v.Major++
+			v.Minor = 0
+			v.Patch = 0
+		}
+		v.Pre = nil
+	case "minor":
+		if len(v.Pre) == 0 || v.Patch != 0 {
+			v.Minor++
+			v.Patch = 0
+		}
+		v.Pre = nil
+	case "patch":
+		if len(v.Pre) == 0 {
+			v.Patch++
+		}
+		v.Pre = nil
+	case "pre":
+		if len(v.Pre) == 0 {
+			if preid == "" {
+				preid = "rc"
+			}
+			v.Patch++
+			v.Pre = []string{preid, "1"}
+		} else if preid != "" && v.Pre[0] != preid {
+			v.Pre = []string{preid, "1"}
+		} else {
+			last := v.Pre[len(v.Pre)-1]
+			if isNumeric(last) {
+				n, err := strconv.ParseUint(last, 10, 64)
+				if err != nil {
+					return err
+				}
+				v.Pre[len(v.Pre)-1] = strconv.FormatUint(n+1, 10)
+			} else {
+				v.Pre = append(v.Pre, "1")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown version component: %s (expected major, minor, patch or pre)", part)
+	}
+
+	v.Counter = 0
+	v.Build = nil
+	v.counterInBuild = false
+	return nil
+}
+
+func (v *Version) Core() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+func (v *Version) String() string {
+	s := v.Core()
+	build := v.Build
+	if v.Counter > 0 {
+		if len(v.Pre) > 0 || len(v.Build) > 0 || v.counterInBuild {
+			build = append([]string{counterIdent, strconv.FormatUint(v.Counter, 10)}, build...)
+		} else {
+			s += fmt.Sprintf(".%d", v.Counter)
+		}
+	}
+	if len(v.Pre) > 0 {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	if len(build) > 0 {
+		s += "+" + strings.Join(build, ".")
+	}
+	return s
+}
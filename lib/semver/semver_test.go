@@ -0,0 +1,147 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		counter uint64
+	}{
+		{"", "0.0.0", 0},
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3\n", "1.2.3", 0},
+		{"1.2.3.4", "1.2.3.4", 4},
+		{"1.2.3-rc.1", "1.2.3-rc.1", 0},
+		{"1.2.3+sha.abc", "1.2.3+sha.abc", 0},
+		{"1.2.3-rc.1+build.4", "1.2.3-rc.1+build.4", 4},
+		{"1.2.3-rc.1+build.4.sha", "1.2.3-rc.1+build.4.sha", 4},
+		{"1.2.3.4-rc.1", "1.2.3-rc.1+build.4", 4},
+		{"1.2.3+build.4", "1.2.3+build.4", 4},
+		{"1.2.3+build.4.sha", "1.2.3+build.4.sha", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %s", tt.in, err)
+			}
+			if got := v.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+			}
+			if v.Counter != tt.counter {
+				t.Errorf("Parse(%q).Counter = %d, want %d", tt.in, v.Counter, tt.counter)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"1.2",
+		"1.2.3.4.5",
+		"a.b.c",
+		"01.2.3",
+		"1.2.3-",
+		"1.2.3-rc..1",
+		"1.2.3-rc.01",
+		"1.2.3+",
+		"1.2.3+a_b",
+	}
+
+	for _, in := range tests {
+		t.Run(in, func(t *testing.T) {
+			if v, err := Parse(in); err == nil {
+				t.Errorf("Parse(%q) = %q, want an error", in, v.String())
+			}
+		})
+	}
+}
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		in    string
+		part  string
+		preid string
+		want  string
+	}{
+		{"1.2.3", "major", "", "2.0.0"},
+		{"1.2.3", "minor", "", "1.3.0"},
+		{"1.2.3", "patch", "", "1.2.4"},
+		{"1.2.3.40", "minor", "", "1.3.0"},
+		{"1.2.3+sha.abc", "patch", "", "1.2.4"},
+		{"2.0.0-rc.1", "major", "", "2.0.0"},
+		{"2.1.0-rc.1", "major", "", "3.0.0"},
+		{"1.3.0-rc.1", "minor", "", "1.3.0"},
+		{"1.3.1-rc.1", "minor", "", "1.4.0"},
+		{"1.2.4-rc.1", "patch", "", "1.2.4"},
+		{"1.2.3", "pre", "", "1.2.4-rc.1"},
+		{"1.2.3", "pre", "beta", "1.2.4-beta.1"},
+		{"1.2.4-rc.1", "pre", "", "1.2.4-rc.2"},
+		{"1.2.4-rc.1", "pre", "rc", "1.2.4-rc.2"},
+		{"1.2.4-rc.1", "pre", "beta", "1.2.4-beta.1"},
+		{"1.2.4-rc", "pre", "", "1.2.4-rc.1"},
+		{"1.2.4-rc.1+build.7", "pre", "", "1.2.4-rc.2"},
+		{"1.2.4-rc.9", "pre", "", "1.2.4-rc.10"},
+		{"1.2.3+build.4", "patch", "", "1.2.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in+" "+tt.part+" "+tt.preid, func(t *testing.T) {
+			v, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %s", tt.in, err)
+			}
+			if err := v.Bump(tt.part, tt.preid); err != nil {
+				t.Fatalf("Bump(%q, %q) returned error: %s", tt.part, tt.preid, err)
+			}
+			if got := v.String(); got != tt.want {
+				t.Errorf("Bump(%q, %q) of %q = %q, want %q", tt.part, tt.preid, tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumpUnknownPart(t *testing.T) {
+	v, err := Parse("1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Bump("build", ""); err == nil {
+		t.Errorf("Bump(%q) returned no error", "build")
+	}
+}
+
+func TestCounter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1.2.3", "1.2.3.1"},
+		{"1.2.3.4", "1.2.3.5"},
+		{"1.2.3-rc.1", "1.2.3-rc.1+build.1"},
+		{"1.2.3-rc.1+build.1", "1.2.3-rc.1+build.2"},
+		{"1.2.3-rc.1+sha", "1.2.3-rc.1+build.1.sha"},
+		{"1.2.3+build.4", "1.2.3+build.5"},
+		{"1.2.3+build.4.sha", "1.2.3+build.5.sha"},
+		{"1.2.3+sha", "1.2.3+build.1.sha"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %s", tt.in, err)
+			}
+			v.Counter++
+			got := v.String()
+			if got != tt.want {
+				t.Errorf("incrementing the counter of %q = %q, want %q", tt.in, got, tt.want)
+			}
+			if _, err := Parse(got); err != nil {
+				t.Errorf("Parse(%q) returned error: %s", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type BuildContext struct {
+	Name      string
+	Profile   string
+	Version   string
+	Commit    string
+	Timestamp int64
+	BuildID   string
+	Root      string
+	TempDir   string
+	Targets   []Target
+	Target    Target
+	Deps      map[string]*BuildContext
+	ctx       context.Context
+}
+
+func NewBuildContext(b *BuildConfig) *BuildContext {
+	ctx := &BuildContext{
+		Name:      b.Name,
+		Timestamp: time.Now().Unix(),
+		BuildID:   strconv.FormatInt(rand.Int63(), 36),
+		Root:      b.RelCfgPath(),
+		Targets:   b.Targets,
+	}
+	ctx.TempDir = filepath.Join(os.TempDir(), "lbt", fmt.Sprintf("%d-%s", ctx.Timestamp, ctx.BuildID))
+
+	if b.Version.Path != "" {
+		by, err := os.ReadFile(b.RelCfgPath(b.Version.Path))
+		if err == nil {
+			ctx.Version = strings.TrimSpace(string(by))
+		}
+	}
+
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = ctx.Root
+	out, err := cmd.Output()
+	if err == nil {
+		ctx.Commit = strings.TrimSpace(string(out))
+	}
+
+	return ctx
+}
+
+func (c *BuildContext) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+func (c *BuildContext) Err() error {
+	if c == nil || c.ctx == nil {
+		return nil
+	}
+	return c.ctx.Err()
+}
+
+func (b *BuildConfig) Command(name string, args ...string) *exec.Cmd {
+	if b.Context == nil || b.Context.ctx == nil {
+		return exec.Command(name, args...)
+	}
+	cmd := exec.CommandContext(b.Context.ctx, name, args...)
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+func (c *BuildContext) Artifacts(project, module string) (string, error) {
+	dep, ok := c.Deps[project]
+	if !ok {
+		return "", fmt.Errorf("%s is not a dependency of %s", project, c.Name)
+	}
+	return filepath.Join(dep.TempDir, c.Target.String(), module), nil
+}
+
+func (c *BuildContext) ModuleDir(module string) string {
+	if c.Target == NoTarget {
+		return filepath.Join(c.TempDir, module)
+	}
+	return filepath.Join(c.TempDir, c.Target.String(), module)
+}
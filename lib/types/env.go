@@ -0,0 +1,111 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+func (b *BuildConfig) Environ(module string) ([]string, error) {
+	env := map[string]string{}
+	recorded := map[string]string{}
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		if b.EnvPassthrough == nil {
+			env[k] = v
+		} else if passthrough(k, b.EnvPassthrough) {
+			env[k] = v
+			recorded[k] = k
+		}
+	}
+
+	target := NoTarget
+	if b.Context != nil {
+		target = b.Context.Target
+	}
+
+	sets := []map[string]string{b.Env}
+	patterns := []string{}
+	for p := range b.TargetEnv {
+		if target != NoTarget && target.Matches(p) {
+			patterns = append(patterns, p)
+		}
+	}
+	SortPatterns(patterns)
+	for _, p := range patterns {
+		sets = append(sets, b.TargetEnv[p])
+	}
+	if mod, err := b.modConfig(module); err == nil {
+		sets = append(sets, mod.Env)
+	}
+
+	for _, set := range sets {
+		for k, v := range set {
+			if b.Context != nil {
+				var err error
+				v, err = b.Context.Expand(v)
+				if err != nil {
+					return nil, fmt.Errorf("env %s: %s", k, err.Error())
+				}
+			}
+			env[k] = v
+			recorded[k] = k + "=" + v
+		}
+	}
+
+	out := []string{}
+	for k, v := range env {
+		out = append(out, k+"="+v)
+	}
+	sort.Strings(out)
+
+	if target != NoTarget {
+		rec := []string{}
+		for _, kv := range recorded {
+			rec = append(rec, kv)
+		}
+		sort.Strings(rec)
+		b.recordEnv(fmt.Sprintf("%s/%s", target.String(), module), rec)
+	}
+	return out, nil
+}
+
+func (b *BuildConfig) ModuleVars(module string) []string {
+	if b.Context == nil {
+		return nil
+	}
+	t := b.Context.Target
+	name := ""
+	if t != NoTarget {
+		name = t.String()
+	}
+	return []string{
+		"LBT_NAME=" + b.Name,
+		"LBT_TARGET=" + name,
+		"LBT_OS=" + string(t.OS),
+		"LBT_ARCH=" + string(t.Arch),
+		"LBT_TEMP_DIR=" + b.TempDir(t),
+		"LBT_MODULE_DIR=" + b.Context.ModuleDir(module),
+	}
+}
+
+func passthrough(name string, allow []string) bool {
+	for _, a := range allow {
+		if ok, err := path.Match(a, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *BuildConfig) recordEnv(key string, env []string) {
+	rootMu.Lock()
+	defer rootMu.Unlock()
+	r := b.root()
+	if r.EffectiveEnv == nil {
+		r.EffectiveEnv = map[string][]string{}
+	}
+	r.EffectiveEnv[key] = env
+}
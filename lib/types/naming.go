@@ -0,0 +1,101 @@
+package types
+
+type ArtifactKind string
+
+const (
+	Executable ArtifactKind = "exe"
+	StaticLib  ArtifactKind = "static"
+	SharedLib  ArtifactKind = "shared"
+	ImportLib  ArtifactKind = "import"
+)
+
+type NamePolicy struct {
+	Prefix string
+	Exe    string
+	Static string
+	Shared string
+	Import string
+}
+
+type Naming struct {
+	Prefix *string `yaml:"prefix,omitempty"`
+	Exe    *string `yaml:"exe,omitempty"`
+	Static *string `yaml:"static,omitempty"`
+	Shared *string `yaml:"shared,omitempty"`
+	Import *string `yaml:"import,omitempty"`
+}
+
+func (t Target) NamePolicy() NamePolicy {
+	switch t.OS {
+	case Windows:
+		return NamePolicy{Prefix: "lib", Exe: ".exe", Static: ".a", Shared: ".dll", Import: ".dll.a"}
+	case MacOS:
+		return NamePolicy{Prefix: "lib", Static: ".a", Shared: ".dylib"}
+	case WASIP1:
+		return NamePolicy{Prefix: "lib", Exe: ".wasm", Static: ".a", Shared: ".so"}
+	}
+	return NamePolicy{Prefix: "lib", Static: ".a", Shared: ".so"}
+}
+
+func (t Target) FileName(name string, kind ArtifactKind) string {
+	return t.NamePolicy().FileName(name, kind)
+}
+
+func (p NamePolicy) FileName(name string, kind ArtifactKind) string {
+	switch kind {
+	case StaticLib:
+		return p.Prefix + name + p.Static
+	case SharedLib:
+		return p.Prefix + name + p.Shared
+	case ImportLib:
+		return p.Prefix + name + p.Import
+	}
+	return name + p.Exe
+}
+
+func (p NamePolicy) With(n Naming) NamePolicy {
+	set := func(dst *string, v *string) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	set(&p.Prefix, n.Prefix)
+	set(&p.Exe, n.Exe)
+	set(&p.Static, n.Static)
+	set(&p.Shared, n.Shared)
+	set(&p.Import, n.Import)
+	return p
+}
+
+func (n Naming) Merge(o Naming) Naming {
+	merge := func(dst **string, v *string) {
+		if v != nil {
+			*dst = v
+		}
+	}
+	merge(&n.Prefix, o.Prefix)
+	merge(&n.Exe, o.Exe)
+	merge(&n.Static, o.Static)
+	merge(&n.Shared, o.Shared)
+	merge(&n.Import, o.Import)
+	return n
+}
+
+func (b *BuildConfig) NamePolicy(t Target) NamePolicy {
+	p := t.NamePolicy()
+	patterns := []string{}
+	for pat := range b.Naming {
+		if t != NoTarget && t.Matches(pat) {
+			patterns = append(patterns, pat)
+		}
+	}
+	SortPatterns(patterns)
+	for _, pat := range patterns {
+		p = p.With(b.Naming[pat])
+	}
+	return p
+}
+
+func (b *BuildConfig) FileName(t Target, name string, kind ArtifactKind) string {
+	return b.NamePolicy(t).FileName(name, kind)
+}
@@ -2,68 +2,65 @@ package types
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"path"
 	"runtime"
+	"slices"
+	"sort"
 	"strings"
-	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type OS string
 type Arch string
 
-var timestamp = fmt.Sprint(time.Now().Unix())
-
 const (
 	Windows OS = "windows"
 	Linux   OS = "linux"
 	MacOS   OS = "darwin"
 	JVM     OS = "jvm"
 	Android OS = "android"
+	FreeBSD OS = "freebsd"
+	OpenBSD OS = "openbsd"
+	NetBSD  OS = "netbsd"
+	JS      OS = "js"
+	WASIP1  OS = "wasip1"
 )
 
 const (
-	AMD64 Arch = "amd64"
-	ARM64 Arch = "arm64"
-	ARM   Arch = "arm"
-	i386  Arch = "i386"
+	AMD64   Arch = "amd64"
+	ARM64   Arch = "arm64"
+	ARM     Arch = "arm"
+	I386    Arch = "i386"
+	RISCV64 Arch = "riscv64"
+	PPC64LE Arch = "ppc64le"
+	S390X   Arch = "s390x"
+	MIPS64  Arch = "mips64"
+	LOONG64 Arch = "loong64"
+	WASM    Arch = "wasm"
 )
 
 func ParseOS(s string) (OS, error) {
-	switch s {
-	case "windows":
-		return Windows, nil
-	case "linux":
-		return Linux, nil
-	case "darwin":
-		return MacOS, nil
-	case "jvm":
-		return JVM, nil
-	case "android":
-		return Android, nil
-	default:
-		return "", fmt.Errorf("unknown OS: %s", s)
+	for _, o := range osTable {
+		if string(o.Name) == s {
+			return o.Name, nil
+		}
 	}
+	return "", fmt.Errorf("unknown OS: %s", s)
 }
 
 func ParseArch(s string) (Arch, error) {
-	switch s {
-	case "amd64":
-		return AMD64, nil
-	case "arm64":
-		return ARM64, nil
-	case "arm":
-		return ARM, nil
-	case "i386":
-		return i386, nil
-	default:
-		return "", fmt.Errorf("unknown arch: %s", s)
+	for _, a := range archTable {
+		if string(a.Name) == s || slices.Contains(a.Aliases, s) {
+			return a.Name, nil
+		}
 	}
+	return "", fmt.Errorf("unknown arch: %s", s)
 }
 
 type Target struct {
-	OS   OS   `yaml:"os"`
-	Arch Arch `yaml:"arch"`
+	OS   OS   `yaml:"os" json:"os"`
+	Arch Arch `yaml:"arch" json:"arch"`
 }
 
 func (t *Target) Validate() error {
@@ -77,6 +74,10 @@ func (t *Target) Validate() error {
 	if err != nil {
 		return err
 	}
+
+	if (t.Arch == WASM) != (t.OS == JS || t.OS == WASIP1) {
+		return fmt.Errorf("invalid target %s: the wasm arch can only be used with the js and wasip1 OS", t.String())
+	}
 	return nil
 }
 
@@ -99,21 +100,137 @@ func (t Target) CleanName(n string, addExe bool) string {
 	return n
 }
 
-func (t Target) TempDir() string {
-	tmpDir := os.TempDir()
-	tS := t.String()
-	if t.Arch == "" && t.OS == "" {
-		tS = ""
+func (t Target) CmpRuntimeOS() bool {
+	goos, _, _ := t.Names(ToolchainGo)
+	return goos == runtime.GOOS
+}
+
+func (t Target) CmpRuntimeArch() bool {
+	_, goarch, _ := t.Names(ToolchainGo)
+	return goarch == runtime.GOARCH
+}
+
+func (t Target) Matches(pattern string) bool {
+	if a, err := ParseArch(pattern); err == nil {
+		return t.Arch == a
+	}
+
+	osPat, archPat, ok := strings.Cut(pattern, "_")
+	if !ok {
+		return matchPart(pattern, string(t.OS)) || matchPart(pattern, string(t.Arch))
+	}
+	if a, err := ParseArch(archPat); err == nil {
+		archPat = string(a)
 	}
-	return filepath.Join(tmpDir, "lbt", timestamp, tS)
+	return matchPart(osPat, string(t.OS)) && matchPart(archPat, string(t.Arch))
 }
 
-func (t Target) CmpRuntimeOS() bool {
-	return string(t.OS) == runtime.GOOS
+func matchPart(pattern, s string) bool {
+	ok, err := path.Match(pattern, s)
+	return err == nil && ok
 }
 
-func (t Target) CmpRuntimeArch() bool {
-	return string(t.Arch) == runtime.GOARCH
+func ValidatePattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid target pattern %q: %s", pattern, err.Error())
+	}
+	if matchCount(pattern) == 0 {
+		return fmt.Errorf("target pattern %q does not match any known target", pattern)
+	}
+	return nil
+}
+
+func matchCount(pattern string) int {
+	n := 0
+	for _, o := range osTable {
+		for _, a := range archTable {
+			if (Target{OS: o.Name, Arch: a.Name}).Matches(pattern) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// SortPatterns orders target patterns from least to most specific, so that
+// settings for narrower patterns are applied last. A pattern is more specific
+// the fewer known targets it matches, and ties are sorted alphabetically.
+func SortPatterns(patterns []string) {
+	counts := map[string]int{}
+	for _, p := range patterns {
+		counts[p] = matchCount(p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		pi, pj := patterns[i], patterns[j]
+		if counts[pi] != counts[pj] {
+			return counts[pi] > counts[pj]
+		}
+		return pi < pj
+	})
+}
+
+func MatchesAny(t Target, patterns []string) bool {
+	for _, p := range patterns {
+		if t.Matches(p) {
+			return true
+		}
+	}
+	return false
+}
+
+type TargetList []Target
+
+type targetMatrix struct {
+	OS      StringList `yaml:"os"`
+	Arch    StringList `yaml:"arch"`
+	Exclude StringList `yaml:"exclude"`
+}
+
+func (l *TargetList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		var targets []Target
+		err := node.Decode(&targets)
+		if err != nil {
+			return err
+		}
+		*l = targets
+		return nil
+	}
+
+	var m targetMatrix
+	err := node.Decode(&m)
+	if err != nil {
+		return err
+	}
+	if len(m.OS) == 0 || len(m.Arch) == 0 {
+		return fmt.Errorf("line %d: a target matrix requires both os and arch", node.Line)
+	}
+	for _, p := range m.Exclude {
+		if err := ValidatePattern(p); err != nil {
+			return fmt.Errorf("line %d: %s", node.Line, err.Error())
+		}
+	}
+
+	targets := TargetList{}
+	for _, o := range m.OS {
+		os, err := ParseOS(o)
+		if err != nil {
+			return fmt.Errorf("line %d: %s", node.Line, err.Error())
+		}
+		for _, a := range m.Arch {
+			arch, err := ParseArch(a)
+			if err != nil {
+				return fmt.Errorf("line %d: %s", node.Line, err.Error())
+			}
+			t := Target{OS: os, Arch: arch}
+			if t.Validate() != nil || MatchesAny(t, m.Exclude) {
+				continue
+			}
+			targets = append(targets, t)
+		}
+	}
+	*l = targets
+	return nil
 }
 
 func ParseTarget(s string) (Target, error) {
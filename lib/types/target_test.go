@@ -0,0 +1,184 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func targetString(l []Target) string {
+	names := []string{}
+	for _, t := range l {
+		names = append(names, t.String())
+	}
+	return strings.Join(names, " ")
+}
+
+func TestTargetListUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "list",
+			src:  "targets:\n  - {os: linux, arch: amd64}\n  - {os: windows, arch: arm64}\n",
+			want: "linux_amd64 windows_arm64",
+		},
+		{
+			name: "matrix",
+			src:  "targets:\n  os: [linux, darwin]\n  arch: [amd64, arm64]\n",
+			want: "linux_amd64 linux_arm64 darwin_amd64 darwin_arm64",
+		},
+		{
+			name: "matrix with scalars and aliases",
+			src:  "targets:\n  os: linux\n  arch: [x86_64, aarch64]\n",
+			want: "linux_amd64 linux_arm64",
+		},
+		{
+			name: "matrix exclude",
+			src:  "targets:\n  os: [linux, windows, darwin]\n  arch: [amd64, arm64]\n  exclude: [windows_arm64, darwin]\n",
+			want: "linux_amd64 linux_arm64 windows_amd64",
+		},
+		{
+			name: "matrix skips invalid pairs",
+			src:  "targets:\n  os: [linux, js]\n  arch: [amd64, wasm]\n",
+			want: "linux_amd64 js_wasm",
+		},
+		{
+			name:    "matrix without arch",
+			src:     "targets:\n  os: [linux]\n",
+			wantErr: true,
+		},
+		{
+			name:    "matrix unknown os",
+			src:     "targets:\n  os: [plan9]\n  arch: [amd64]\n",
+			wantErr: true,
+		},
+		{
+			name:    "matrix invalid exclude",
+			src:     "targets:\n  os: [linux]\n  arch: [amd64]\n  exclude: [plan9_*]\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg struct {
+				Targets TargetList `yaml:"targets"`
+			}
+			err := yaml.Unmarshal([]byte(tt.src), &cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got targets %q, want an error", targetString(cfg.Targets))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal returned error: %s", err)
+			}
+			if got := targetString(cfg.Targets); got != tt.want {
+				t.Errorf("targets = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetMatches(t *testing.T) {
+	linux := Target{OS: Linux, Arch: AMD64}
+	win := Target{OS: Windows, Arch: ARM64}
+
+	tests := []struct {
+		pattern string
+		target  Target
+		want    bool
+	}{
+		{"linux_amd64", linux, true},
+		{"linux_arm64", linux, false},
+		{"linux", linux, true},
+		{"linux", win, false},
+		{"amd64", linux, true},
+		{"x86_64", linux, true},
+		{"linux_x86_64", linux, true},
+		{"linux_*", linux, true},
+		{"*_arm64", win, true},
+		{"*_arm64", linux, false},
+		{"*", linux, true},
+		{"win*", win, true},
+		{"win*", linux, false},
+		{"*_aarch64", win, true},
+		{"[", linux, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.target.String(), func(t *testing.T) {
+			if got := tt.target.Matches(tt.pattern); got != tt.want {
+				t.Errorf("%s.Matches(%q) = %v, want %v", tt.target.String(), tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	for _, p := range []string{"linux", "amd64", "linux_*", "*_arm64", "*", "js_wasm"} {
+		if err := ValidatePattern(p); err != nil {
+			t.Errorf("ValidatePattern(%q) returned error: %s", p, err)
+		}
+	}
+	for _, p := range []string{"plan9", "linux_sparc", "[", "linux-amd64"} {
+		if err := ValidatePattern(p); err == nil {
+			t.Errorf("ValidatePattern(%q) returned no error", p)
+		}
+	}
+}
+
+func TestSortPatterns(t *testing.T) {
+	patterns := []string{"linux_amd64", "*", "linux", "amd64", "linux_*", "*_amd64"}
+	SortPatterns(patterns)
+	want := "* *_amd64 amd64 linux linux_* linux_amd64"
+	if got := strings.Join(patterns, " "); got != want {
+		t.Errorf("SortPatterns = %q, want %q", got, want)
+	}
+}
+
+func TestOverrideSpecificity(t *testing.T) {
+	m := ModuleConfig{
+		Name: "gobuild",
+		Config: map[string]interface{}{
+			"ldflags": "base",
+			"flags":   map[string]interface{}{"a": "base"},
+		},
+		Overrides: map[string]map[string]interface{}{
+			"linux_amd64": {"ldflags": "linux_amd64"},
+			"*":           {"ldflags": "any", "flags": map[string]interface{}{"b": "any"}},
+			"linux":       {"ldflags": "linux", "flags": map[string]interface{}{"a": "linux"}},
+			"amd64":       {"ldflags": "amd64", "extra": "amd64"},
+		},
+	}
+
+	tests := []struct {
+		target Target
+		want   string
+	}{
+		{Target{OS: Linux, Arch: AMD64}, "map[extra:amd64 flags:map[a:linux b:any] ldflags:linux_amd64]"},
+		{Target{OS: Linux, Arch: ARM64}, "map[flags:map[a:linux b:any] ldflags:linux]"},
+		{Target{OS: Windows, Arch: AMD64}, "map[extra:amd64 flags:map[a:base b:any] ldflags:amd64]"},
+		{Target{OS: MacOS, Arch: ARM64}, "map[flags:map[a:base b:any] ldflags:any]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target.String(), func(t *testing.T) {
+			got := fmt.Sprint(m.forTarget(tt.target).Config)
+			if got != tt.want {
+				t.Errorf("config = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got := fmt.Sprint(m.Config); got != "map[flags:map[a:base] ldflags:base]" {
+		t.Errorf("forTarget modified the base config: %s", got)
+	}
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/lspaccatrosi16/lbt/lib/validate"
+)
+
+func (c *BuildContext) Expand(s string) (string, error) {
+	s, err := c.expandTemplate(s)
+	if err != nil {
+		return "", err
+	}
+	return expandEnv(s)
+}
+
+func (c *BuildContext) expandTemplate(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %s", s, err.Error())
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err = tmpl.Execute(buf, c)
+	if err != nil {
+		return "", fmt.Errorf("cannot expand %q: %s", s, err.Error())
+	}
+	return buf.String(), nil
+}
+
+func expandEnv(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], "$${") {
+			out.WriteString("${")
+			i += 2
+			continue
+		}
+
+		if !strings.HasPrefix(s[i:], "${") {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		end := strings.Index(s[i:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable in %q", s)
+		}
+
+		expr := s[i+2 : i+end]
+		name, def, hasDef := strings.Cut(expr, ":-")
+		if name == "" {
+			return "", fmt.Errorf("empty variable name in %q", s)
+		}
+
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			if !hasDef {
+				return "", fmt.Errorf("undefined environment variable %s in %q", name, s)
+			}
+			val = def
+		}
+
+		out.WriteString(val)
+		i += end
+	}
+	return out.String(), nil
+}
+
+func (c *BuildContext) expandValue(v interface{}, path string) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		if !strings.Contains(v, "${") && !strings.Contains(v, "{{") {
+			return v, nil
+		}
+		s, err := c.Expand(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		return validate.Expanded(s), nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			ev, err := c.expandValue(e, path+"."+k)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = ev
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			ev, err := c.expandValue(e, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = ev
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
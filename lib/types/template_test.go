@@ -0,0 +1,130 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("LBT_TEST_A", "alpha")
+	t.Setenv("LBT_TEST_EMPTY", "")
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "plain", want: "plain"},
+		{in: "${LBT_TEST_A}", want: "alpha"},
+		{in: "x-${LBT_TEST_A}-y", want: "x-alpha-y"},
+		{in: "${LBT_TEST_A}${LBT_TEST_A}", want: "alphaalpha"},
+		{in: "${LBT_TEST_EMPTY}", want: ""},
+		{in: "${LBT_TEST_EMPTY:-def}", want: ""},
+		{in: "${LBT_TEST_UNSET:-def}", want: "def"},
+		{in: "${LBT_TEST_UNSET:-}", want: ""},
+		{in: "$LBT_TEST_A", want: "$LBT_TEST_A"},
+		{in: "$${LBT_TEST_A}", want: "${LBT_TEST_A}"},
+		{in: "$${LBT_TEST_UNSET}", want: "${LBT_TEST_UNSET}"},
+		{in: "${LBT_TEST_UNSET}", wantErr: true},
+		{in: "${LBT_TEST_A", wantErr: true},
+		{in: "${}", wantErr: true},
+		{in: "${:-def}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := expandEnv(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expandEnv(%q) = %q, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandEnv(%q) returned error: %s", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("expandEnv(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	t.Setenv("LBT_TEST_A", "alpha")
+	c := &BuildContext{Name: "app", Version: "1.2.3", Target: Target{OS: "linux", Arch: "amd64"}}
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "out/{{.Version}}/{{.Target.OS}}", want: "out/1.2.3/linux"},
+		{in: "{{.Name}}-${LBT_TEST_A}", want: "app-alpha"},
+		{in: `{{"{{"}}.Name}}`, want: "{{.Name}}"},
+		{in: "{{.Missing}}", wantErr: true},
+		{in: "{{.Name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := c.Expand(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expand(%q) = %q, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand(%q) returned error: %s", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandValue(t *testing.T) {
+	t.Setenv("LBT_TEST_A", "alpha")
+	c := &BuildContext{Name: "app"}
+
+	in := map[string]interface{}{
+		"name":  "{{.Name}}",
+		"jobs":  4,
+		"flags": []interface{}{"-D${LBT_TEST_A}", "-O2", true},
+		"nested": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"path": "$${LBT_TEST_A}/${LBT_TEST_A}"},
+			},
+		},
+	}
+	want := map[string]interface{}{
+		"name":  "app",
+		"jobs":  4,
+		"flags": []interface{}{"-Dalpha", "-O2", true},
+		"nested": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{"path": "${LBT_TEST_A}/alpha"},
+			},
+		},
+	}
+
+	got, err := c.expandValue(in, "config")
+	if err != nil {
+		t.Fatalf("expandValue returned error: %s", err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expandValue = %v, want %v", got, want)
+	}
+
+	in["nested"].(map[string]interface{})["list"].([]interface{})[0].(map[string]interface{})["path"] = "${LBT_TEST_UNSET}"
+	_, err = c.expandValue(in, "config")
+	if err == nil {
+		t.Fatal("expandValue returned no error for an undefined variable")
+	}
+	if want := "config.nested.list[0].path: "; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("expandValue error = %q, want it to start with %q", err, want)
+	}
+}
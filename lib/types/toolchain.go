@@ -0,0 +1,166 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+)
+
+type Toolchain string
+
+const (
+	ToolchainGo   Toolchain = "go"
+	ToolchainOdin Toolchain = "odin"
+	ToolchainV    Toolchain = "v"
+	ToolchainC    Toolchain = "c"
+)
+
+var Toolchains = []Toolchain{ToolchainGo, ToolchainOdin, ToolchainV, ToolchainC}
+
+type OSInfo struct {
+	Name  OS
+	Names map[Toolchain]string
+}
+
+type ArchInfo struct {
+	Name    Arch
+	Aliases []string
+	Names   map[Toolchain]string
+}
+
+var osTable = []OSInfo{
+	{Name: Windows, Names: map[Toolchain]string{ToolchainGo: "windows", ToolchainOdin: "windows", ToolchainV: "windows", ToolchainC: "windows-gnu"}},
+	{Name: Linux, Names: map[Toolchain]string{ToolchainGo: "linux", ToolchainOdin: "linux", ToolchainV: "linux", ToolchainC: "linux-gnu"}},
+	{Name: MacOS, Names: map[Toolchain]string{ToolchainGo: "darwin", ToolchainOdin: "darwin", ToolchainV: "macos", ToolchainC: "macos"}},
+	{Name: JVM, Names: map[Toolchain]string{}},
+	{Name: Android, Names: map[Toolchain]string{ToolchainGo: "android", ToolchainV: "android", ToolchainC: "linux-android"}},
+	{Name: FreeBSD, Names: map[Toolchain]string{ToolchainGo: "freebsd", ToolchainOdin: "freebsd", ToolchainV: "freebsd", ToolchainC: "freebsd"}},
+	{Name: OpenBSD, Names: map[Toolchain]string{ToolchainGo: "openbsd", ToolchainOdin: "openbsd", ToolchainV: "openbsd", ToolchainC: "openbsd"}},
+	{Name: NetBSD, Names: map[Toolchain]string{ToolchainGo: "netbsd", ToolchainOdin: "netbsd", ToolchainV: "netbsd", ToolchainC: "netbsd"}},
+	{Name: JS, Names: map[Toolchain]string{ToolchainGo: "js", ToolchainOdin: "js", ToolchainC: "emscripten"}},
+	{Name: WASIP1, Names: map[Toolchain]string{ToolchainGo: "wasip1", ToolchainOdin: "wasi", ToolchainV: "wasm32_wasi", ToolchainC: "wasi"}},
+}
+
+var archTable = []ArchInfo{
+	{Name: AMD64, Aliases: []string{"x86_64"}, Names: map[Toolchain]string{ToolchainGo: "amd64", ToolchainOdin: "amd64", ToolchainV: "amd64", ToolchainC: "x86_64"}},
+	{Name: ARM64, Aliases: []string{"aarch64"}, Names: map[Toolchain]string{ToolchainGo: "arm64", ToolchainOdin: "arm64", ToolchainV: "arm64", ToolchainC: "aarch64"}},
+	{Name: ARM, Names: map[Toolchain]string{ToolchainGo: "arm", ToolchainOdin: "arm32", ToolchainV: "arm32", ToolchainC: "arm"}},
+	{Name: I386, Aliases: []string{"386"}, Names: map[Toolchain]string{ToolchainGo: "386", ToolchainOdin: "i386", ToolchainV: "i386", ToolchainC: "x86"}},
+	{Name: RISCV64, Names: map[Toolchain]string{ToolchainGo: "riscv64", ToolchainOdin: "riscv64", ToolchainV: "rv64", ToolchainC: "riscv64"}},
+	{Name: PPC64LE, Names: map[Toolchain]string{ToolchainGo: "ppc64le", ToolchainV: "ppc64le", ToolchainC: "powerpc64le"}},
+	{Name: S390X, Names: map[Toolchain]string{ToolchainGo: "s390x", ToolchainV: "s390x", ToolchainC: "s390x"}},
+	{Name: MIPS64, Names: map[Toolchain]string{ToolchainGo: "mips64", ToolchainC: "mips64"}},
+	{Name: LOONG64, Names: map[Toolchain]string{ToolchainGo: "loong64", ToolchainV: "loongarch64", ToolchainC: "loongarch64"}},
+	{Name: WASM, Names: map[Toolchain]string{ToolchainGo: "wasm", ToolchainOdin: "wasm32", ToolchainV: "wasm32", ToolchainC: "wasm32"}},
+}
+
+var validPairs = map[Toolchain]map[OS][]Arch{
+	ToolchainGo: {
+		Windows: {AMD64, ARM64, ARM, I386},
+		Linux:   {AMD64, ARM64, ARM, I386, RISCV64, PPC64LE, S390X, MIPS64, LOONG64},
+		MacOS:   {AMD64, ARM64},
+		Android: {AMD64, ARM64, ARM, I386},
+		FreeBSD: {AMD64, ARM64, ARM, I386, RISCV64},
+		OpenBSD: {AMD64, ARM64, ARM, I386, RISCV64, PPC64LE},
+		NetBSD:  {AMD64, ARM64, ARM, I386},
+		JS:      {WASM},
+		WASIP1:  {WASM},
+	},
+	ToolchainOdin: {
+		Windows: {AMD64, ARM64, I386},
+		Linux:   {AMD64, ARM64, ARM, I386, RISCV64},
+		MacOS:   {AMD64, ARM64},
+		FreeBSD: {AMD64, ARM64, I386},
+		OpenBSD: {AMD64},
+		NetBSD:  {AMD64, ARM64},
+		JS:      {WASM},
+		WASIP1:  {WASM},
+	},
+	ToolchainV: {
+		Windows: {AMD64, ARM64, I386},
+		Linux:   {AMD64, ARM64, ARM, I386, RISCV64, PPC64LE, S390X, LOONG64},
+		MacOS:   {AMD64, ARM64},
+		Android: {AMD64, ARM64, ARM, I386},
+		FreeBSD: {AMD64, ARM64},
+		OpenBSD: {AMD64, ARM64},
+		NetBSD:  {AMD64},
+		WASIP1:  {WASM},
+	},
+	ToolchainC: {
+		Windows: {AMD64, ARM64, I386},
+		Linux:   {AMD64, ARM64, ARM, I386, RISCV64, PPC64LE, S390X, MIPS64, LOONG64},
+		MacOS:   {AMD64, ARM64},
+		Android: {AMD64, ARM64, ARM, I386},
+		FreeBSD: {AMD64, ARM64, ARM, I386, RISCV64, PPC64LE},
+		OpenBSD: {AMD64, ARM64, ARM, I386, RISCV64},
+		NetBSD:  {AMD64, ARM64, ARM, I386},
+		JS:      {WASM},
+		WASIP1:  {WASM},
+	},
+}
+
+func OSList() []OSInfo {
+	return osTable
+}
+
+func ArchList() []ArchInfo {
+	return archTable
+}
+
+func lookupOS(o OS) (OSInfo, bool) {
+	for _, i := range osTable {
+		if i.Name == o {
+			return i, true
+		}
+	}
+	return OSInfo{}, false
+}
+
+func lookupArch(a Arch) (ArchInfo, bool) {
+	for _, i := range archTable {
+		if i.Name == a {
+			return i, true
+		}
+	}
+	return ArchInfo{}, false
+}
+
+func (t Target) Names(tc Toolchain) (string, string, bool) {
+	oi, ok := lookupOS(t.OS)
+	if !ok {
+		return "", "", false
+	}
+	ai, ok := lookupArch(t.Arch)
+	if !ok {
+		return "", "", false
+	}
+
+	os, osOk := oi.Names[tc]
+	arch, archOk := ai.Names[tc]
+	if !osOk || !archOk {
+		return os, arch, false
+	}
+
+	if pairs, ok := validPairs[tc]; ok && !slices.Contains(pairs[t.OS], t.Arch) {
+		return os, arch, false
+	}
+	return os, arch, true
+}
+
+func (t Target) Supports(tc Toolchain) bool {
+	_, _, ok := t.Names(tc)
+	return ok
+}
+
+func (t Target) Triple() (string, error) {
+	os, arch, ok := t.Names(ToolchainC)
+	if !ok {
+		return "", fmt.Errorf("target %s has no C target triple", t.String())
+	}
+	if t.OS == Android && t.Arch == ARM {
+		return "arm-linux-androideabi", nil
+	}
+	if t.OS == Linux && t.Arch == ARM {
+		return "arm-linux-gnueabihf", nil
+	}
+	return fmt.Sprintf("%s-%s", arch, os), nil
+}
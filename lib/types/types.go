@@ -2,45 +2,304 @@ package types
 
 import (
 	"fmt"
+	"maps"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/lspaccatrosi16/lbt/lib/log"
+	"github.com/lspaccatrosi16/lbt/lib/validate"
 	"gopkg.in/yaml.v3"
 )
 
 type ModuleConfig struct {
-	Name   string                 `yaml:"name"`
-	Config map[string]interface{} `yaml:"config"`
+	Name      string                            `yaml:"name" validate:"required"`
+	Config    map[string]interface{}            `yaml:"config"`
+	Targets   StringList                        `yaml:"targets,omitempty"`
+	When      string                            `yaml:"when,omitempty"`
+	Overrides map[string]map[string]interface{} `yaml:"overrides,omitempty"`
+	Env       map[string]string                 `yaml:"env,omitempty"`
+	Hooks     Hooks                             `yaml:"hooks,omitempty"`
+	Positions map[string]validate.Position      `yaml:"-"`
+}
+
+type Hooks struct {
+	Before    StringList `yaml:"before,omitempty"`
+	After     StringList `yaml:"after,omitempty"`
+	OnFailure StringList `yaml:"onFailure,omitempty"`
+}
+
+func (h Hooks) Empty() bool {
+	return len(h.Before) == 0 && len(h.After) == 0 && len(h.OnFailure) == 0
+}
+
+func (m ModuleConfig) forTarget(t Target) ModuleConfig {
+	patterns := []string{}
+	for p := range m.Overrides {
+		if t.Matches(p) {
+			patterns = append(patterns, p)
+		}
+	}
+	if len(patterns) == 0 {
+		return m
+	}
+	SortPatterns(patterns)
+
+	pos := maps.Clone(m.Positions)
+	for _, p := range patterns {
+		m.Config = MergeMaps(m.Config, m.Overrides[p])
+		for k, v := range validate.Sub(m.Positions, "overrides."+p) {
+			if k == "" {
+				continue
+			}
+			if strings.HasPrefix(k, "[") {
+				pos["config"+k] = v
+			} else {
+				pos["config."+k] = v
+			}
+		}
+	}
+	m.Positions = pos
+	return m
+}
+
+func MergeMaps(base, overlay map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base)+len(overlay))
+	for k, v := range base {
+		out[k] = v
+	}
+
+	for k, v := range overlay {
+		bm, bok := out[k].(map[string]interface{})
+		om, ook := v.(map[string]interface{})
+		if bok && ook {
+			out[k] = MergeMaps(bm, om)
+		} else {
+			out[k] = v
+		}
+	}
+
+	return out
 }
 
 type VerConfig struct {
 	Path string `yaml:"path"`
-	VtS  string `yaml:"type"`
+	VtS  string `yaml:"type" validate:"oneof=buildstr buildint semver"`
 }
 
 func NewBuildConfig(loc string) *BuildConfig {
 	return &BuildConfig{loc: loc}
 }
 
+type Profile struct {
+	Targets TargetList     `yaml:"targets,omitempty"`
+	Modules []ModuleConfig `yaml:"modules,omitempty"`
+}
+
+type StringList []string
+
+func (s *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = StringList{node.Value}
+		return nil
+	}
+	var l []string
+	err := node.Decode(&l)
+	if err != nil {
+		return err
+	}
+	*s = l
+	return nil
+}
+
+type WorkspaceMember struct {
+	Name      string     `yaml:"name,omitempty"`
+	Config    string     `yaml:"config"`
+	DependsOn StringList `yaml:"dependsOn,omitempty"`
+}
+
 type BuildConfig struct {
-	Name        string         `yaml:"name"`
-	Targets     []Target       `yaml:"targets"`
-	Modules     []ModuleConfig `yaml:"modules"`
-	IncludeDirs []string       `yaml:"includeDirs"`
-	Version     VerConfig      `yaml:"version"`
-	Produced    []string
-	loc         string
+	Extends        StringList                   `yaml:"extends,omitempty"`
+	Include        StringList                   `yaml:"include,omitempty"`
+	Name           string                       `yaml:"name"`
+	Targets        TargetList                   `yaml:"targets"`
+	Modules        []ModuleConfig               `yaml:"modules"`
+	IncludeDirs    []string                     `yaml:"includeDirs,omitempty"`
+	Version        VerConfig                    `yaml:"version,omitempty"`
+	Profiles       map[string]Profile           `yaml:"profiles,omitempty"`
+	Workspace      []WorkspaceMember            `yaml:"workspace,omitempty"`
+	Env            map[string]string            `yaml:"env,omitempty"`
+	TargetEnv      map[string]map[string]string `yaml:"targetEnv,omitempty"`
+	EnvPassthrough StringList                   `yaml:"envPassthrough,omitempty"`
+	ModulePaths    []string                     `yaml:"modulePaths,omitempty"`
+	Naming         map[string]Naming            `yaml:"naming,omitempty"`
+	Produced       []string                     `yaml:"-"`
+	EffectiveEnv   map[string][]string          `yaml:"-"`
+	Packages       map[string]map[string]string `yaml:"-"`
+	Context        *BuildContext                `yaml:"-"`
+	loc            string
+	parent         *BuildConfig
+	once           map[string]*onceResult
 }
 
 func (b *BuildConfig) RelCfgPath(paths ...string) string {
 	return filepath.Join(append([]string{b.loc}, paths...)...)
 }
 
+func (b *BuildConfig) TempDir(t Target) string {
+	dir := filepath.Join(os.TempDir(), "lbt")
+	if b.Context != nil {
+		dir = b.Context.TempDir
+	}
+	if t == NoTarget {
+		return dir
+	}
+	return filepath.Join(dir, t.String())
+}
+
+func (b *BuildConfig) ForTarget(t Target) *BuildConfig {
+	nb := *b
+	if b.Context != nil {
+		ctx := *b.Context
+		ctx.Target = t
+		nb.Context = &ctx
+	}
+	root := b.root()
+	nb.Modules = make([]ModuleConfig, len(root.Modules))
+	for i, m := range root.Modules {
+		nb.Modules[i] = m.forTarget(t)
+	}
+	nb.parent = root
+	return &nb
+}
+
+func (b *BuildConfig) ModuleHooks(name string) Hooks {
+	mod, err := b.modConfig(name)
+	if err != nil {
+		return Hooks{}
+	}
+	return mod.Hooks
+}
+
+func (b *BuildConfig) ModuleTargeted(name string) bool {
+	mod, err := b.modConfig(name)
+	if err != nil || b.Context == nil {
+		return true
+	}
+	return len(mod.Targets) == 0 || MatchesAny(b.Context.Target, mod.Targets)
+}
+
+func (b *BuildConfig) ModuleEnabled(name string) (bool, error) {
+	mod, err := b.modConfig(name)
+	if err != nil || b.Context == nil {
+		return true, nil
+	}
+
+	if !b.ModuleTargeted(name) {
+		return false, nil
+	}
+	if mod.When == "" {
+		return true, nil
+	}
+
+	res, err := b.Context.Expand(mod.When)
+	if err != nil {
+		return false, fmt.Errorf("module %s: when: %s", name, err.Error())
+	}
+	enabled, err := strconv.ParseBool(strings.TrimSpace(res))
+	if err != nil {
+		return false, fmt.Errorf("module %s: when must evaluate to true or false, got %q", name, res)
+	}
+	return enabled, nil
+}
+
+func (b *BuildConfig) root() *BuildConfig {
+	if b.parent == nil {
+		return b
+	}
+	return b.parent
+}
+
+var rootMu sync.Mutex
+
+func (b *BuildConfig) AddProduced(path string) {
+	rootMu.Lock()
+	defer rootMu.Unlock()
+	r := b.root()
+	r.Produced = append(r.Produced, path)
+}
+
+func (b *BuildConfig) AddPackages(module string, pkgs map[string]string) {
+	rootMu.Lock()
+	defer rootMu.Unlock()
+	r := b.root()
+	if r.Packages == nil {
+		r.Packages = map[string]map[string]string{}
+	}
+	key := module
+	if b.Context != nil && b.Context.Target != NoTarget {
+		key = fmt.Sprintf("%s/%s", b.Context.Target.String(), module)
+	}
+	r.Packages[key] = pkgs
+}
+
+type onceResult struct {
+	once sync.Once
+	val  interface{}
+	err  error
+}
+
+func (b *BuildConfig) RunOnce(key string, f func() bool) bool {
+	ok, _ := b.Memo(key, func() (interface{}, error) {
+		return f(), nil
+	})
+	return ok.(bool)
+}
+
+func (b *BuildConfig) Memo(key string, f func() (interface{}, error)) (interface{}, error) {
+	rootMu.Lock()
+	r := b.root()
+	if r.once == nil {
+		r.once = map[string]*onceResult{}
+	}
+	o, ok := r.once[key]
+	if !ok {
+		o = &onceResult{}
+		r.once[key] = o
+	}
+	rootMu.Unlock()
+
+	o.once.Do(func() {
+		o.val, o.err = f()
+	})
+	return o.val, o.err
+}
+
 func GetModConfig[T any](b *BuildConfig, name string) (*T, error) {
-	cfg, err := b.modConfig(name)
+	mod, err := b.modConfig(name)
 	if err != nil {
 		return nil, err
 	}
+	cfg := mod.Config
+	if cfg == nil {
+		cfg = map[string]interface{}{}
+	}
+
+	if b.Context != nil {
+		expanded, err := b.Context.expandValue(cfg, "config")
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %s", name, err.Error())
+		}
+		cfg = expanded.(map[string]interface{})
+	}
+
+	errs := validate.Validate(reflect.TypeFor[T](), cfg, validate.Sub(mod.Positions, "config"))
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("module %s has an invalid config:\n%s", name, errs.Error())
+	}
 	by, err := yaml.Marshal(cfg)
 	if err != nil {
 		return nil, err
@@ -54,15 +313,42 @@ func GetModConfig[T any](b *BuildConfig, name string) (*T, error) {
 	return &out, nil
 }
 
-func (b *BuildConfig) modConfig(name string) (map[string]interface{}, error) {
-	for _, mod := range b.Modules {
+func (b *BuildConfig) modConfig(name string) (*ModuleConfig, error) {
+	for i, mod := range b.Modules {
 		if mod.Name == name {
-			return mod.Config, nil
+			return &b.Modules[i], nil
 		}
 	}
 	return nil, fmt.Errorf("module %s has not been configured", name)
 }
 
+func NewInstance(m Module) Module {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Pointer {
+		return m
+	}
+	nv := reflect.New(v.Elem().Type())
+	nv.Elem().Set(v.Elem())
+	return nv.Interface().(Module)
+}
+
+type ConfigurableModule interface {
+	ConfigType() reflect.Type
+	ResolvedConfig() any
+}
+
+type TargetedModule interface {
+	SupportsTarget(t Target) bool
+}
+
+func CheckTarget(m Module, t Target) error {
+	tm, ok := m.(TargetedModule)
+	if !ok || tm.SupportsTarget(t) {
+		return nil
+	}
+	return fmt.Errorf("module %s does not support target %s", m.Name(), t.String())
+}
+
 type Module interface {
 	Name() string
 	RunModule(*log.Logger, Target) bool
@@ -12,6 +12,10 @@ func Copy(dst string, src string) error {
 		return err
 	}
 	if s.IsDir() {
+		err = os.MkdirAll(dst, 0755)
+		if err != nil {
+			return err
+		}
 		return cpy_dir(dst, src)
 	} else {
 		return cpy_file(dst, src)
@@ -28,7 +32,12 @@ func cpy_dir(dst, src string) error {
 	for _, d := range de {
 		srcPath := filepath.Join(src, d.Name())
 		dstPath := filepath.Join(dst, d.Name())
-		if d.IsDir() {
+		if d.Type()&os.ModeSymlink != 0 {
+			err = cpy_link(dstPath, srcPath)
+			if err != nil {
+				return err
+			}
+		} else if d.IsDir() {
 			err = os.MkdirAll(dstPath, 0755)
 			if err != nil {
 				return err
@@ -41,6 +50,15 @@ func cpy_dir(dst, src string) error {
 	return nil
 }
 
+func cpy_link(dstPath, srcPath string) error {
+	target, err := os.Readlink(srcPath)
+	if err != nil {
+		return err
+	}
+	os.Remove(dstPath)
+	return os.Symlink(target, dstPath)
+}
+
 func cpy_file(dstPath, srcPath string) error {
 	srcF, err := os.Open(srcPath)
 	if err != nil {
@@ -0,0 +1,416 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Position struct {
+	File string
+	Line int
+}
+
+func (p Position) String() string {
+	if p.File == "" && p.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
+type FieldError struct {
+	Path string
+	Pos  Position
+	Msg  string
+}
+
+func (e FieldError) Error() string {
+	s := e.Msg
+	if e.Path != "" {
+		s = e.Path + ": " + s
+	}
+	if p := e.Pos.String(); p != "" {
+		s = p + ": " + s
+	}
+	return s
+}
+
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	lines := []string{}
+	for _, fe := range e {
+		lines = append(lines, fe.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func Positions(node *yaml.Node, file string) map[string]Position {
+	pos := map[string]Position{}
+	collectPositions(node, "", file, pos)
+	return pos
+}
+
+func collectPositions(node *yaml.Node, path, file string, pos map[string]Position) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		collectPositions(node.Content[0], path, file, pos)
+		return
+	}
+
+	pos[path] = Position{File: file, Line: node.Line}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := node.Content[i]
+			cp := joinPath(path, k.Value)
+			collectPositions(node.Content[i+1], cp, file, pos)
+			pos[cp] = Position{File: file, Line: k.Line}
+		}
+	case yaml.SequenceNode:
+		for i, c := range node.Content {
+			collectPositions(c, fmt.Sprintf("%s[%d]", path, i), file, pos)
+		}
+	}
+}
+
+func Sub(pos map[string]Position, prefix string) map[string]Position {
+	out := map[string]Position{}
+	for k, v := range pos {
+		if k == prefix {
+			out[""] = v
+		} else if strings.HasPrefix(k, prefix+".") {
+			out[k[len(prefix)+1:]] = v
+		} else if strings.HasPrefix(k, prefix+"[") {
+			out[k[len(prefix):]] = v
+		}
+	}
+	return out
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func Validate(t reflect.Type, v interface{}, pos map[string]Position) Errors {
+	vd := &validator{pos: pos}
+	vd.value(t, v, "")
+	sort.SliceStable(vd.errs, func(i, j int) bool {
+		return vd.errs[i].Pos.Line < vd.errs[j].Pos.Line
+	})
+	return vd.errs
+}
+
+type validator struct {
+	pos  map[string]Position
+	errs Errors
+}
+
+func (vd *validator) errorf(path, format string, a ...any) {
+	vd.errs = append(vd.errs, FieldError{
+		Path: path,
+		Pos:  vd.lookup(path),
+		Msg:  fmt.Sprintf(format, a...),
+	})
+}
+
+func (vd *validator) lookup(path string) Position {
+	for {
+		if p, ok := vd.pos[path]; ok {
+			return p
+		}
+		if path == "" {
+			return Position{}
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			path = ""
+		} else {
+			path = path[:i]
+		}
+	}
+}
+
+// Expanded is a config string produced by variable or template expansion.
+// It is validated and marshalled as if its text had been written as a
+// plain YAML scalar, so "${JOBS}" can fill an integer field.
+type Expanded string
+
+func (e Expanded) Value() interface{} {
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(e), &v); err == nil {
+		switch v.(type) {
+		case bool, int, float64:
+			return v
+		}
+	}
+	return string(e)
+}
+
+func (e Expanded) MarshalYAML() (interface{}, error) {
+	if _, ok := e.Value().(string); ok {
+		return string(e), nil
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: string(e)}, nil
+}
+
+func resolve(v interface{}) interface{} {
+	if e, ok := v.(Expanded); ok {
+		return e.Value()
+	}
+	return v
+}
+
+var unmarshalerType = reflect.TypeFor[yaml.Unmarshaler]()
+
+func (vd *validator) value(t reflect.Type, v interface{}, path string) {
+	v = resolve(v)
+	if v == nil {
+		return
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			vd.errorf(path, "expected a mapping, got %s", describe(v))
+			return
+		}
+		vd.structFields(t, m, path)
+	case reflect.Slice, reflect.Array:
+		l, ok := v.([]interface{})
+		if !ok {
+			vd.errorf(path, "expected a list, got %s", describe(v))
+			return
+		}
+		for i, e := range l {
+			vd.value(t.Elem(), e, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			vd.errorf(path, "expected a mapping, got %s", describe(v))
+			return
+		}
+		for k, e := range m {
+			vd.value(t.Elem(), e, joinPath(path, k))
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			vd.errorf(path, "expected a boolean, got %s", describe(v))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, ok := v.(int); !ok {
+			vd.errorf(path, "expected an integer, got %s", describe(v))
+		}
+	case reflect.String:
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			vd.errorf(path, "expected a string, got %s", describe(v))
+		}
+	}
+}
+
+type Field struct {
+	name  string
+	typ   reflect.Type
+	rules []string
+}
+
+func Fields(t reflect.Type) []Field {
+	fields := []Field{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.ToLower(f.Name)
+		if tag, ok := f.Tag.Lookup("yaml"); ok {
+			n, _, _ := strings.Cut(tag, ",")
+			if n == "-" {
+				continue
+			}
+			if n != "" {
+				name = n
+			}
+		}
+
+		var rules []string
+		if tag := f.Tag.Get("validate"); tag != "" {
+			rules = strings.Split(tag, ",")
+		}
+		fields = append(fields, Field{name: name, typ: f.Type, rules: rules})
+	}
+	return fields
+}
+
+func (f Field) Name() string {
+	return f.name
+}
+
+func (f Field) Type() reflect.Type {
+	return f.typ
+}
+
+func (f Field) Required() bool {
+	for _, r := range f.rules {
+		if r == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Field) OneOf() []string {
+	for _, r := range f.rules {
+		if opts, ok := strings.CutPrefix(r, "oneof="); ok {
+			return strings.Fields(opts)
+		}
+	}
+	return nil
+}
+
+func (vd *validator) structFields(t reflect.Type, m map[string]interface{}, path string) {
+	fields := Fields(t)
+	known := []string{}
+	for _, f := range fields {
+		known = append(known, f.name)
+		fp := joinPath(path, f.name)
+		v, present := m[f.name]
+		v = resolve(v)
+
+		if f.Required() && (!present || isEmpty(v)) {
+			vd.errorf(fp, "field is required")
+			continue
+		}
+		if !present {
+			continue
+		}
+
+		if opts := f.OneOf(); len(opts) > 0 && !isEmpty(v) {
+			s := fmt.Sprint(v)
+			found := false
+			for _, o := range opts {
+				if o == s {
+					found = true
+				}
+			}
+			if !found {
+				vd.errorf(fp, "invalid value \"%s\" (expected one of: %s)", s, strings.Join(opts, ", "))
+				continue
+			}
+		}
+
+		vd.value(f.typ, v, fp)
+	}
+
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		found := false
+		for _, n := range known {
+			if n == k {
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+		if s := Suggest(k, known); s != "" {
+			vd.errorf(joinPath(path, k), "unknown field \"%s\", did you mean \"%s\"?", k, s)
+		} else {
+			vd.errorf(joinPath(path, k), "unknown field \"%s\"", k)
+		}
+	}
+}
+
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func describe(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "a mapping"
+	case []interface{}:
+		return "a list"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int:
+		return "an integer"
+	case float64:
+		return "a number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func Suggest(s string, options []string) string {
+	best := ""
+	bestDist := len(s)/3 + 1
+	for _, o := range options {
+		d := distance(strings.ToLower(s), strings.ToLower(o))
+		if d <= bestDist {
+			best = o
+			bestDist = d
+		}
+	}
+	return best
+}
+
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
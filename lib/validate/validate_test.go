@@ -0,0 +1,168 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type testConfig struct {
+	Name  string            `yaml:"name" validate:"required"`
+	Mode  string            `yaml:"mode" validate:"oneof=debug release"`
+	Jobs  int               `yaml:"jobs"`
+	Strip bool              `yaml:"strip"`
+	Flags []string          `yaml:"flags"`
+	Env   map[string]string `yaml:"env"`
+	Sub   *testSub          `yaml:"sub"`
+}
+
+type testSub struct {
+	Path string `yaml:"path" validate:"required"`
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "valid",
+			src:  "name: a\nmode: release\njobs: 4\nstrip: true\nflags: [-O2]\nenv: {A: b}\nsub: {path: x}\n",
+			want: nil,
+		},
+		{
+			name: "unknown field with suggestion",
+			src:  "name: a\nnmae: b\n",
+			want: []string{`cfg.yaml:2: nmae: unknown field "nmae", did you mean "name"?`},
+		},
+		{
+			name: "unknown field without suggestion",
+			src:  "name: a\ncompletely: b\n",
+			want: []string{`cfg.yaml:2: completely: unknown field "completely"`},
+		},
+		{
+			name: "missing required",
+			src:  "mode: debug\n",
+			want: []string{`cfg.yaml:1: name: field is required`},
+		},
+		{
+			name: "empty required",
+			src:  "name: \"\"\n",
+			want: []string{`cfg.yaml:1: name: field is required`},
+		},
+		{
+			name: "nested required",
+			src:  "name: a\nsub:\n  other: x\n",
+			want: []string{
+				`cfg.yaml:2: sub.path: field is required`,
+				`cfg.yaml:3: sub.other: unknown field "other"`,
+			},
+		},
+		{
+			name: "oneof",
+			src:  "name: a\nmode: fast\n",
+			want: []string{`cfg.yaml:2: mode: invalid value "fast" (expected one of: debug, release)`},
+		},
+		{
+			name: "type mismatches",
+			src:  "name: a\njobs: many\nstrip: 1\nflags: -O2\nenv:\n  A: [b]\n",
+			want: []string{
+				`cfg.yaml:2: jobs: expected an integer, got a string`,
+				`cfg.yaml:3: strip: expected a boolean, got an integer`,
+				`cfg.yaml:4: flags: expected a list, got a string`,
+				`cfg.yaml:6: env.A: expected a string, got a list`,
+			},
+		},
+		{
+			name: "list element position",
+			src:  "name: a\nflags:\n  - -O2\n  - [x]\n",
+			want: []string{`cfg.yaml:4: flags[1]: expected a string, got a list`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var node yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.src), &node); err != nil {
+				t.Fatal(err)
+			}
+			var v interface{}
+			if err := node.Decode(&v); err != nil {
+				t.Fatal(err)
+			}
+
+			errs := Validate(reflect.TypeFor[testConfig](), v, Positions(&node, "cfg.yaml"))
+			got := []string{}
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Validate() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("error %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateExpanded(t *testing.T) {
+	v := map[string]interface{}{
+		"name":  Expanded("a"),
+		"mode":  Expanded("release"),
+		"jobs":  Expanded("4"),
+		"strip": Expanded("true"),
+	}
+	if errs := Validate(reflect.TypeFor[testConfig](), v, nil); len(errs) > 0 {
+		t.Fatalf("Validate() returned errors:\n%s", errs.Error())
+	}
+
+	v["jobs"] = Expanded("many")
+	v["name"] = Expanded("")
+	errs := Validate(reflect.TypeFor[testConfig](), v, nil)
+	if len(errs) != 2 {
+		t.Fatalf("Validate() = %q, want 2 errors", errs.Error())
+	}
+
+	by, err := yaml.Marshal(map[string]interface{}{"name": Expanded("1.0"), "jobs": Expanded("4")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out testConfig
+	if err := yaml.Unmarshal(by, &out); err != nil {
+		t.Fatalf("unmarshalling expanded values: %s", err)
+	}
+	if out.Name != "1.0" || out.Jobs != 4 {
+		t.Errorf("unmarshalled %+v, want name 1.0 and jobs 4", out)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	options := []string{"name", "mode", "jobs", "sources", "includeDirs"}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"name", "name"},
+		{"nmae", "name"},
+		{"Mode", "mode"},
+		{"job", "jobs"},
+		{"source", "sources"},
+		{"includedir", "includeDirs"},
+		{"include_dirs", "includeDirs"},
+		{"x", ""},
+		{"completely", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Suggest(tt.in, options); got != tt.want {
+				t.Errorf("Suggest(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package lbt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/lspaccatrosi16/lbt/lib/cache"
+	"github.com/lspaccatrosi16/lbt/lib/config"
+	"github.com/lspaccatrosi16/lbt/lib/modules"
+	"github.com/lspaccatrosi16/lbt/lib/modules/cached"
+	"github.com/lspaccatrosi16/lbt/lib/modules/output"
+	"github.com/lspaccatrosi16/lbt/lib/progress"
+	"github.com/lspaccatrosi16/lbt/lib/runner"
+	"github.com/lspaccatrosi16/lbt/lib/types"
+	"github.com/lspaccatrosi16/lbt/lib/util"
+)
+
+type Options struct {
+	ConfigPath string
+	Profile    string
+	Targets    []string
+	Force      bool
+	KeepTemp   bool
+	Output     io.Writer
+}
+
+type Result struct {
+	Name      string
+	Artifacts []string
+	Cached    bool
+	TempDir   string
+}
+
+func RegisterModule(m types.Module) error {
+	return modules.Register(m)
+}
+
+func Build(ctx context.Context, opts Options) (Result, error) {
+	res := Result{}
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+
+	cfg, err := config.ParseConfig(opts.ConfigPath, opts.Profile)
+	if err != nil {
+		return res, err
+	}
+	res.Name = cfg.Name
+	cfg.Context.SetContext(ctx)
+	if opts.KeepTemp {
+		res.TempDir = cfg.Context.TempDir
+	}
+
+	if len(cfg.Workspace) > 0 {
+		return buildWorkspace(ctx, cfg, opts, res)
+	}
+
+	modList, buildMeta, usesCache, err := prepare(cfg, opts.Force, true)
+	if err != nil {
+		return res, err
+	}
+	res.Cached = usesCache
+
+	err = runner.RunModules(cfg, modList, usesCache, opts.Targets, opts.KeepTemp, opts.Output)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return res, ctxErr
+	}
+	if err != nil {
+		return res, err
+	}
+
+	res.Artifacts, err = writeArtifacts(cfg, buildMeta)
+	return res, err
+}
+
+func buildWorkspace(ctx context.Context, ws *types.BuildConfig, opts Options, res Result) (Result, error) {
+	members, err := config.ParseWorkspace(ws)
+	if err != nil {
+		return res, err
+	}
+
+	ml := runner.Logger(opts.Output)
+	job := progress.NewJob(ws.Name)
+	builds := []*runner.Build{}
+	metas := []cache.BuildMeta{}
+	res.Cached = true
+
+	depended := map[*types.BuildContext]bool{}
+	for _, member := range members {
+		member.Context.SetContext(ctx)
+		for _, d := range member.Context.Deps {
+			depended[d] = true
+		}
+	}
+
+	for _, member := range members {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		allowCache := len(member.Context.Deps) == 0 && !depended[member.Context]
+		modList, buildMeta, usesCache, err := prepare(member, opts.Force, allowCache)
+		if err != nil {
+			return res, fmt.Errorf("%s: %s", member.Name, err.Error())
+		}
+
+		b, err := runner.NewBuild(job.NewChild(member.Name), ml.ChildLogger(member.Name), member, modList, usesCache, opts.Targets)
+		if err != nil {
+			return res, fmt.Errorf("%s: %s", member.Name, err.Error())
+		}
+
+		builds = append(builds, b)
+		metas = append(metas, buildMeta)
+		res.Cached = res.Cached && usesCache
+	}
+
+	cleanupJob := runner.NewCleanup(ml, ws, res.Cached, opts.KeepTemp)
+	err = runner.Render(fmt.Sprintf("workspace %s", ws.Name), opts.Output, job, cleanupJob, builds...)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return res, ctxErr
+	}
+	if err != nil {
+		return res, err
+	}
+
+	for i, member := range members {
+		produced, err := writeArtifacts(member, metas[i])
+		if err != nil {
+			return res, err
+		}
+		res.Artifacts = append(res.Artifacts, produced...)
+	}
+
+	return res, nil
+}
+
+func prepare(config *types.BuildConfig, force bool, allowCache bool) (map[string]types.Module, cache.BuildMeta, bool, error) {
+	buildMeta := cache.BuildMeta{
+		BuildName: config.Name,
+		BuildTime: time.Now().Unix(),
+		Profile:   config.Context.Profile,
+	}
+
+	modList, err := modules.ForConfig(config)
+	if err != nil {
+		return nil, buildMeta, false, err
+	}
+
+	var usesCache bool
+
+	if len(config.IncludeDirs) > 0 {
+		buildMeta.Hash, err = cache.HashDirectories(config, config.IncludeDirs)
+		if err != nil {
+			return nil, buildMeta, false, err
+		}
+
+		prevMeta, err := cache.GetLatestBuildArtifact(config.Name)
+		if err != nil {
+			return nil, buildMeta, false, err
+		}
+
+		_, oErr := types.GetModConfig[output.ModuleConfig](config, "output")
+		if prevMeta != nil && prevMeta.Hash == buildMeta.Hash && prevMeta.Profile == buildMeta.Profile && oErr == nil && !force && allowCache {
+			var outDir interface{}
+			for _, m := range config.Modules {
+				if m.Name == "output" {
+					outDir = m.Config["outDir"]
+				}
+			}
+
+			modList = map[string]types.Module{
+				"getCached": &cached.GetCachedModule{Meta: prevMeta},
+				"output":    &output.OutputModule{},
+			}
+			config.Modules = []types.ModuleConfig{
+				{Name: "getCached", Config: map[string]interface{}{}},
+				{Name: "output", Config: map[string]interface{}{"module": "getCached", "outDir": outDir}},
+			}
+			buildMeta = *prevMeta
+			usesCache = true
+		}
+	}
+
+	return modList, buildMeta, usesCache, nil
+}
+
+func writeArtifacts(config *types.BuildConfig, buildMeta cache.BuildMeta) ([]string, error) {
+	cd, err := cache.GetArtifactCacheDir(buildMeta.BuildName)
+	if err != nil {
+		return nil, err
+	}
+
+	pName := []string{}
+	for _, p := range config.Produced {
+		name := filepath.Base(p)
+		pName = append(pName, name)
+		err = util.Copy(filepath.Join(cd, name), p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	buildMeta.Objects = pName
+	if len(config.EffectiveEnv) > 0 {
+		buildMeta.Env = config.EffectiveEnv
+	}
+	if len(config.Packages) > 0 {
+		buildMeta.Packages = config.Packages
+	}
+
+	err = cache.WriteBuildMeta(buildMeta)
+	return config.Produced, err
+}